@@ -9,12 +9,15 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/example/go-scaffold/data"
+	"github.com/example/go-scaffold/pkg/document"
 	"github.com/example/go-scaffold/pkg/embedding"
 	"github.com/example/go-scaffold/pkg/hipporag"
 	"github.com/example/go-scaffold/pkg/llm"
+	"github.com/example/go-scaffold/pkg/loader"
 )
 
 func main() {
@@ -30,7 +33,19 @@ func main() {
 	}
 
 	// 创建客户端
-	embeddingClient := embedding.NewOpenAIClient(apiKey, "text-embedding-3-small")
+	var embeddingClient embedding.Client = embedding.NewOpenAIClient(apiKey, "text-embedding-3-small")
+
+	// 设置 EMBEDDING_CACHE_PATH 时把向量缓存到本地文件，重复运行时不再重复调用 embedding API
+	var embeddingCache *embedding.CachedClient
+	if cachePath := os.Getenv("EMBEDDING_CACHE_PATH"); cachePath != "" {
+		cached, err := embedding.NewCachedClient(embeddingClient, "", cachePath)
+		if err != nil {
+			log.Fatalf("打开向量缓存失败: %v", err)
+		}
+		defer cached.Close()
+		embeddingCache = cached
+		embeddingClient = cached
+	}
 	llmClient := llm.NewOpenAIClient(apiKey, "gpt-4o-mini")
 
 	// 创建 HippoRAG
@@ -41,18 +56,54 @@ func main() {
 	config.TopKChunks = 15   // 增加到 15，确保能检索到所有相关文档
 	config.PPRDamping = 0.5  // 降低阻尼系数，让分数传播更广
 
+	// 设置 OPENIE_CACHE_DIR 时缓存 OpenIE 抽取结果，重新索引时只对新的文档块调用 LLM
+	config.OpenIECacheDir = os.Getenv("OPENIE_CACHE_DIR")
+
+	// PROMPT_LANGUAGE 选择提示词语言（default / zh / en），PROMPT_DIR 指定覆盖模板目录
+	if language := os.Getenv("PROMPT_LANGUAGE"); language != "" {
+		config.PromptLanguage = language
+	}
+	config.PromptDir = os.Getenv("PROMPT_DIR")
+	// NewHippoRAG 遇到无法加载的提示词只打印警告，演示程序直接退出
+	if _, err := hipporag.LoadPrompts(config); err != nil {
+		log.Fatalf("加载提示词失败: %v", err)
+	}
+
 	rag := hipporag.NewHippoRAG(config, embeddingClient, llmClient)
 
 	// 索引文档
 	ctx := context.Background()
-	fmt.Println("\n📚 测试文档:")
-	for i, doc := range data.TestDocuments {
-		fmt.Printf("  文档%d: %s\n", i+1, doc)
+	docs := loadDocuments()
+
+	// 设置 HIPPORAG_INDEX_DIR 时复用已保存的索引，避免重复执行 OpenIE 和向量化
+	// 加载后再插入语料，只有索引中还没有的文档会被索引，有新文档时重新保存
+	indexDir := os.Getenv("HIPPORAG_INDEX_DIR")
+	loaded := indexDir != "" && indexExists(indexDir)
+	if loaded {
+		fmt.Printf("\n📂 从 %s 加载索引...\n", indexDir)
+		if err := rag.Load(indexDir); err != nil {
+			log.Fatalf("加载索引失败: %v", err)
+		}
 	}
-
-	if err := rag.Index(ctx, data.TestDocuments); err != nil {
+	indexed := len(rag.Documents())
+	if err := rag.Insert(ctx, docs); err != nil {
 		log.Fatalf("索引失败: %v", err)
 	}
+	added := len(rag.Documents()) - indexed
+	if loaded {
+		fmt.Printf("\n📄 语料中有 %d 个文档不在已保存的索引中，已增量索引\n", added)
+	}
+	if indexDir != "" && (!loaded || added > 0) {
+		if err := rag.Save(indexDir); err != nil {
+			log.Fatalf("保存索引失败: %v", err)
+		}
+		fmt.Printf("\n💾 索引已保存到 %s\n", indexDir)
+	}
+
+	if embeddingCache != nil {
+		cacheStats := embeddingCache.Stats()
+		fmt.Printf("\n📦 向量缓存: 命中 %d, 未命中 %d\n", cacheStats.Hits, cacheStats.Misses)
+	}
 
 	// 显示统计信息
 	stats := rag.Stats(ctx)
@@ -92,3 +143,31 @@ func main() {
 		}
 	}
 }
+
+// indexExists 判断目录中是否已有保存的索引
+func indexExists(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "graph.json"))
+	return err == nil
+}
+
+// loadDocuments 加载语料：设置 CORPUS_PATH 时从文件、目录或 glob 模式加载，否则使用内置测试文档
+func loadDocuments() []document.Document {
+	corpusPath := os.Getenv("CORPUS_PATH")
+	if corpusPath == "" {
+		fmt.Println("\n📚 测试文档:")
+		for i, doc := range data.TestDocuments {
+			fmt.Printf("  文档%d: %s\n", i+1, doc)
+		}
+		return document.FromTexts(data.TestDocuments)
+	}
+
+	docs, err := loader.Load(corpusPath, nil)
+	if err != nil {
+		log.Fatalf("加载语料失败: %v", err)
+	}
+	if len(docs) == 0 {
+		log.Fatalf("加载语料失败: %s 中没有可加载的文档", corpusPath)
+	}
+	fmt.Printf("\n📚 从 %s 加载了 %d 个文档\n", corpusPath, len(docs))
+	return docs
+}
@@ -12,8 +12,11 @@ import (
 	"strings"
 
 	"github.com/example/go-scaffold/data"
+	"github.com/example/go-scaffold/pkg/document"
 	"github.com/example/go-scaffold/pkg/embedding"
 	"github.com/example/go-scaffold/pkg/llm"
+	"github.com/example/go-scaffold/pkg/loader"
+	"github.com/example/go-scaffold/pkg/prompt"
 	"github.com/example/go-scaffold/pkg/rag"
 )
 
@@ -30,23 +33,51 @@ func main() {
 	}
 
 	// 创建客户端
-	embeddingClient := embedding.NewOpenAIClient(apiKey, "text-embedding-3-small")
+	var embeddingClient embedding.Client = embedding.NewOpenAIClient(apiKey, "text-embedding-3-small")
+
+	// 设置 EMBEDDING_CACHE_PATH 时把向量缓存到本地文件，重复运行时不再重复调用 embedding API
+	var embeddingCache *embedding.CachedClient
+	if cachePath := os.Getenv("EMBEDDING_CACHE_PATH"); cachePath != "" {
+		cached, err := embedding.NewCachedClient(embeddingClient, "", cachePath)
+		if err != nil {
+			log.Fatalf("打开向量缓存失败: %v", err)
+		}
+		defer cached.Close()
+		embeddingCache = cached
+		embeddingClient = cached
+	}
 	llmClient := llm.NewOpenAIClient(apiKey, "gpt-4o-mini")
 
 	// 创建传统 RAG
 	traditionalRAG := rag.NewTraditionalRAG(embeddingClient, llmClient, 3)
 
+	// PROMPT_LANGUAGE 选择提示词语言（default / zh / en），PROMPT_DIR 指定覆盖模板目录
+	if language := os.Getenv("PROMPT_LANGUAGE"); language != "" || os.Getenv("PROMPT_DIR") != "" {
+		prompts, err := prompt.NewRegistry(language)
+		if err != nil {
+			log.Fatalf("加载提示词失败: %v", err)
+		}
+		if dir := os.Getenv("PROMPT_DIR"); dir != "" {
+			if err := prompts.LoadDir(dir); err != nil {
+				log.Fatalf("加载提示词失败: %v", err)
+			}
+		}
+		traditionalRAG.SetPrompts(prompts)
+	}
+
 	// 索引文档
 	ctx := context.Background()
-	fmt.Println("\n📚 测试文档:")
-	for i, doc := range data.TestDocuments {
-		fmt.Printf("  文档%d: %s\n", i+1, doc)
-	}
+	docs := loadDocuments()
 
-	if err := traditionalRAG.Index(ctx, data.TestDocuments); err != nil {
+	if err := traditionalRAG.Insert(ctx, docs); err != nil {
 		log.Fatalf("索引失败: %v", err)
 	}
 
+	if embeddingCache != nil {
+		cacheStats := embeddingCache.Stats()
+		fmt.Printf("\n📦 向量缓存: 命中 %d, 未命中 %d\n", cacheStats.Hits, cacheStats.Misses)
+	}
+
 	// 交互式问答
 	fmt.Println("\n" + strings.Repeat("=", 60))
 	fmt.Println("💬 进入交互模式（输入 'quit' 或 'exit' 退出）")
@@ -76,3 +107,25 @@ func main() {
 		}
 	}
 }
+
+// loadDocuments 加载语料：设置 CORPUS_PATH 时从文件、目录或 glob 模式加载，否则使用内置测试文档
+func loadDocuments() []document.Document {
+	corpusPath := os.Getenv("CORPUS_PATH")
+	if corpusPath == "" {
+		fmt.Println("\n📚 测试文档:")
+		for i, doc := range data.TestDocuments {
+			fmt.Printf("  文档%d: %s\n", i+1, doc)
+		}
+		return document.FromTexts(data.TestDocuments)
+	}
+
+	docs, err := loader.Load(corpusPath, nil)
+	if err != nil {
+		log.Fatalf("加载语料失败: %v", err)
+	}
+	if len(docs) == 0 {
+		log.Fatalf("加载语料失败: %s 中没有可加载的文档", corpusPath)
+	}
+	fmt.Printf("\n📚 从 %s 加载了 %d 个文档\n", corpusPath, len(docs))
+	return docs
+}
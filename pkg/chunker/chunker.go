@@ -0,0 +1,174 @@
+package chunker
+
+// chunker.go - 文档分块接口
+// 用途：把文档切分成适合向量化和 OpenIE 的文本块，并记录每块在原文中的位置
+// 主要功能：
+// - Chunker 接口：Chunk 返回文本块（已清理空白）及其在原始文本中的字节区间
+// - New: 按名称创建分块器（rune / sentence / paragraph / token）
+// - RuneChunker: 按字符数固定大小切分（带重叠），不会切断多字节字符
+// - 句子分块见 sentence.go，段落 / Markdown 标题分块见 paragraph.go，按 token 数分块见 token.go
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/example/go-scaffold/pkg/utils"
+)
+
+// 分块策略名称
+const (
+	StrategyRune      = "rune"
+	StrategySentence  = "sentence"
+	StrategyParagraph = "paragraph"
+	StrategyToken     = "token"
+)
+
+// Chunker 文档分块器
+type Chunker interface {
+	// Chunk 切分文本，返回的块按在原文中的位置排序；空白文本返回空结果
+	Chunk(text string) []utils.TextChunk
+}
+
+// New 按策略名称创建分块器
+// size: 每块的最大字符数（token 策略为 token 数，使用 ApproxTokenizer；<= 0 表示不限制）；overlap: 相邻块的重叠长度
+func New(strategy string, size, overlap int) (Chunker, error) {
+	switch strategy {
+	case StrategyRune, "":
+		return NewRuneChunker(size, overlap), nil
+	case StrategySentence:
+		return NewSentenceChunker(size, overlap), nil
+	case StrategyParagraph:
+		return NewParagraphChunker(size, overlap), nil
+	case StrategyToken:
+		return NewTokenChunker(nil, size, overlap), nil
+	default:
+		return nil, fmt.Errorf("unknown chunker: %q", strategy)
+	}
+}
+
+// RuneChunker 按字符数固定大小切分
+type RuneChunker struct {
+	size    int
+	overlap int
+}
+
+// NewRuneChunker 创建固定大小分块器
+// size: 每块字符数；overlap: 块之间的重叠字符数
+func NewRuneChunker(size, overlap int) *RuneChunker {
+	return &RuneChunker{size: size, overlap: overlap}
+}
+
+// Chunk 切分文本
+func (c *RuneChunker) Chunk(text string) []utils.TextChunk {
+	d := newDocument(text)
+	if d.cleaned == "" {
+		return nil
+	}
+	return d.split(span{0, len(d.cleaned)}, c.size, c.overlap)
+}
+
+// span 清理后文本中的字节区间 [start, end)
+type span struct {
+	start, end int
+}
+
+// document 清理后的文本，以及清理后每个字节在原始文本中的偏移
+type document struct {
+	raw     string
+	cleaned string
+	offsets []int
+}
+
+func newDocument(text string) *document {
+	cleaned, offsets := utils.CleanTextWithOffsets(text)
+	return &document{raw: text, cleaned: cleaned, offsets: offsets}
+}
+
+// chunk 把清理后文本的 [start, end) 转换为文本块（去掉首尾空格）
+func (d *document) chunk(start, end int) (utils.TextChunk, bool) {
+	for start < end && d.cleaned[start] == ' ' {
+		start++
+	}
+	for end > start && d.cleaned[end-1] == ' ' {
+		end--
+	}
+	if start >= end {
+		return utils.TextChunk{}, false
+	}
+
+	return utils.TextChunk{
+		Text:  d.cleaned[start:end],
+		Start: d.offsets[start],
+		End:   d.offsets[end-1] + 1,
+	}, true
+}
+
+// split 把区间按字符数固定大小切分
+func (d *document) split(s span, size, overlap int) []utils.TextChunk {
+	// 区间内的文本已清理，utils.ChunkTextSpans 返回的偏移就是相对 s.start 的偏移
+	var chunks []utils.TextChunk
+	for _, part := range utils.ChunkTextSpans(d.cleaned[s.start:s.end], size, overlap) {
+		if chunk, ok := d.chunk(s.start+part.Start, s.start+part.End); ok {
+			chunks = append(chunks, chunk)
+		}
+	}
+	return chunks
+}
+
+// runeLen 区间的字符数
+func (d *document) runeLen(start, end int) int {
+	return utf8.RuneCountInString(d.cleaned[start:end])
+}
+
+// pack 把连续的片段（句子、段落）合并成不超过 size 个字符的块
+// 相邻块以整片段为单位重叠，重叠部分不超过 overlap 个字符；单个片段超过 size 时按字符切分
+func (d *document) pack(units []span, size, overlap int) []utils.TextChunk {
+	if len(units) == 0 {
+		return nil
+	}
+	if size <= 0 {
+		chunk, ok := d.chunk(units[0].start, units[len(units)-1].end)
+		if !ok {
+			return nil
+		}
+		return []utils.TextChunk{chunk}
+	}
+
+	var chunks []utils.TextChunk
+	var current []span
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		if chunk, ok := d.chunk(current[0].start, current[len(current)-1].end); ok {
+			chunks = append(chunks, chunk)
+		}
+	}
+
+	for _, unit := range units {
+		if d.runeLen(unit.start, unit.end) > size {
+			flush()
+			current = nil
+			chunks = append(chunks, d.split(unit, size, overlap)...)
+			continue
+		}
+
+		if len(current) > 0 && d.runeLen(current[0].start, unit.end) > size {
+			flush()
+
+			// 保留末尾不超过 overlap 个字符的片段作为下一块的开头
+			keep := len(current)
+			for keep > 0 && d.runeLen(current[keep-1].start, current[len(current)-1].end) <= overlap {
+				keep--
+			}
+			current = current[keep:]
+			for len(current) > 0 && d.runeLen(current[0].start, unit.end) > size {
+				current = current[1:]
+			}
+		}
+		current = append(current, unit)
+	}
+	flush()
+
+	return chunks
+}
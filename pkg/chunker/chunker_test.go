@@ -0,0 +1,244 @@
+package chunker
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/example/go-scaffold/pkg/utils"
+)
+
+// 已清理空白的中英混合文本（原始偏移与清理后偏移相同）
+// 空格都在英文单词前，与单词合为一个 token，因此块首尾去掉空格不会丢掉整个 token
+const cleanMixedText = "爱因斯坦提出了相对论。Albert Einstein developed the theory of relativity in Bern and Berlin." +
+	"居里夫人发现了镭和钋，两次获得诺贝尔奖。Marie Curie was the first person to win two Nobel Prizes." +
+	"牛顿提出了万有引力定律(law of universal gravitation)，发表于1687年。"
+
+// 已清理空白的中文文本
+const cleanChineseText = "爱因斯坦提出了相对论，并于1921年获得诺贝尔物理学奖。居里夫人发现了镭和钋，两次获得诺贝尔奖。" +
+	"牛顿提出了万有引力定律和三大运动定律，发表于《自然哲学的数学原理》。"
+
+// runeTokenizer 每个字符一个 token（token 都在字符边界上；文本中的空格会被块去掉，测试文本不含空格）
+type runeTokenizer struct{}
+
+func (runeTokenizer) Tokenize(text string) []Token {
+	var tokens []Token
+	for i, r := range text {
+		tokens = append(tokens, Token{i, i + utf8.RuneLen(r)})
+	}
+	return tokens
+}
+
+// TestTokenChunkerBudget 中文和中英混合文本的每块都不超过 size 个 token，相邻块重叠不超过 overlap 个 token，块之间没有遗漏
+func TestTokenChunkerBudget(t *testing.T) {
+	tokenizer := NewApproxTokenizer()
+
+	for name, text := range map[string]string{"chinese": cleanChineseText, "mixed": cleanMixedText} {
+		tokens := tokenizer.Tokenize(text)
+		for _, size := range []int{3, 5, 8, 16, 64} {
+			for _, overlap := range []int{0, 2, 4, size} {
+				chunks := NewTokenChunker(tokenizer, size, overlap).Chunk(text)
+				if len(chunks) < 2 {
+					t.Fatalf("%s size %d overlap %d: got %d chunks, want several", name, size, overlap, len(chunks))
+				}
+
+				var prev []int
+				for i, chunk := range chunks {
+					covered := coveredTokens(tokens, chunk)
+					if len(covered) == 0 || len(covered) > size {
+						t.Errorf("%s size %d overlap %d chunk %d: %d tokens", name, size, overlap, i, len(covered))
+					}
+					// 重新分词（块作为独立文本发送给模型）也不超过 size
+					if n := len(tokenizer.Tokenize(chunk.Text)); n > size {
+						t.Errorf("%s size %d overlap %d chunk %d: %q is %d tokens", name, size, overlap, i, chunk.Text, n)
+					}
+					if i > 0 {
+						shared := sharedTokens(prev, covered)
+						if overlap < size && shared > overlap || overlap >= size && shared > 0 {
+							t.Errorf("%s size %d overlap %d chunk %d: overlaps previous by %d tokens", name, size, overlap, i, shared)
+						}
+						if covered[0] > prev[len(prev)-1]+1 {
+							t.Errorf("%s size %d overlap %d chunk %d: tokens %d..%d skipped", name, size, overlap, i, prev[len(prev)-1]+1, covered[0]-1)
+						}
+					}
+					prev = covered
+				}
+
+				if first := coveredTokens(tokens, chunks[0]); first[0] != 0 || prev[len(prev)-1] != len(tokens)-1 {
+					t.Errorf("%s size %d overlap %d: chunks do not cover the whole text", name, size, overlap)
+				}
+			}
+		}
+	}
+
+	// 纯中文文本的块不超过字节级 BPE 的上限（每个字节最多一个 token）
+	for _, chunk := range NewTokenChunker(nil, 32, 8).Chunk("爱因斯坦提出了相对论，居里夫人发现了镭和钋，牛顿提出了万有引力定律。") {
+		if len(chunk.Text) > 32 {
+			t.Errorf("chunk %q is %d bytes, want at most 32", chunk.Text, len(chunk.Text))
+		}
+	}
+}
+
+// TestTokenChunkerOverlap token 都在字符边界上时，每块恰好 size 个 token（最后一块除外），相邻块恰好重叠 overlap 个 token
+func TestTokenChunkerOverlap(t *testing.T) {
+	tokenizer := runeTokenizer{}
+	tokens := tokenizer.Tokenize(cleanChineseText)
+
+	tests := []struct {
+		size    int
+		overlap int
+		want    int // 期望的相邻块重叠 token 数
+	}{
+		{size: 8, overlap: 0, want: 0},
+		{size: 8, overlap: 3, want: 3},
+		{size: 16, overlap: 5, want: 5},
+		{size: 5, overlap: 4, want: 4},
+		{size: 5, overlap: 5, want: 0}, // 重叠不小于块大小时不重叠
+		{size: 5, overlap: -1, want: 0},
+	}
+
+	for _, tt := range tests {
+		chunks := NewTokenChunker(tokenizer, tt.size, tt.overlap).Chunk(cleanChineseText)
+		if len(chunks) < 2 {
+			t.Fatalf("size %d overlap %d: got %d chunks, want several", tt.size, tt.overlap, len(chunks))
+		}
+
+		var prev []int
+		for i, chunk := range chunks {
+			covered := coveredTokens(tokens, chunk)
+			if len(covered) > tt.size || len(covered) < tt.size && i < len(chunks)-1 {
+				t.Errorf("size %d overlap %d chunk %d: %d tokens", tt.size, tt.overlap, i, len(covered))
+			}
+			if i > 0 {
+				if got := sharedTokens(prev, covered); got != tt.want {
+					t.Errorf("size %d overlap %d chunk %d: overlaps previous by %d tokens, want %d", tt.size, tt.overlap, i, got, tt.want)
+				}
+			}
+			prev = covered
+		}
+		if prev[len(prev)-1] != len(tokens)-1 {
+			t.Errorf("size %d overlap %d: chunks do not cover the whole text", tt.size, tt.overlap)
+		}
+	}
+}
+
+// TestTokenChunkerWideRune 单个字符的 token 数超过 size 时，整个字符作为一块（不切断字符）
+func TestTokenChunkerWideRune(t *testing.T) {
+	chunks := NewTokenChunker(nil, 2, 1).Chunk("相对论")
+	var got []string
+	for _, chunk := range chunks {
+		got = append(got, chunk.Text)
+	}
+	if strings.Join(got, "|") != "相|对|论" {
+		t.Errorf("chunks = %q, want one per character", got)
+	}
+}
+
+// TestTokenChunkerSingleChunk 文本不超过 size 个 token（或 size <= 0）时整体作为一块
+func TestTokenChunkerSingleChunk(t *testing.T) {
+	text := "  爱因斯坦\n提出了 相对论  "
+	for _, size := range []int{0, 100} {
+		chunks := NewTokenChunker(nil, size, 10).Chunk(text)
+		if len(chunks) != 1 || chunks[0].Text != "爱因斯坦 提出了 相对论" {
+			t.Errorf("size %d: got %+v", size, chunks)
+		}
+	}
+	if chunks := NewTokenChunker(nil, 10, 2).Chunk(" \n\t "); len(chunks) != 0 {
+		t.Errorf("blank text: got %+v", chunks)
+	}
+}
+
+// TestApproxTokenizer 近似分词：英文单词连同前导空格一个 token，非 ASCII 字符每个字节一个 token
+func TestApproxTokenizer(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"the theory", []string{"the", " theory"}},
+		{"relativity", []string{"rela", "tivi", "ty"}},
+		{"in 1905, Ulm", []string{"in", " ", "190", "5", ",", " Ulm"}},
+		{"相对论", []string{"\xe7", "\x9b", "\xb8", "\xe5", "\xaf", "\xb9", "\xe8", "\xae", "\xba"}},
+		{"Curie,  镭", []string{"Curie", ",", "  ", "\xe9", "\x95", "\xad"}},
+		{"café", []string{"caf", "\xc3", "\xa9"}},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, token := range NewApproxTokenizer().Tokenize(tt.text) {
+			got = append(got, tt.text[token.Start:token.End])
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+// TestChunkerOffsets 所有分块策略返回的字节区间都落在字符边界上，并指回原始文本中对应的内容
+func TestChunkerOffsets(t *testing.T) {
+	texts := map[string]string{
+		"chinese": "爱因斯坦提出了相对论。\n\n他于1921年获得诺贝尔物理学奖！居里夫人发现了镭？牛顿……提出了万有引力定律。",
+		"mixed": "# 物理学家\n\n  Albert Einstein 提出了\t相对论。  He was born in Ulm.\r\n\r\n" +
+			"## 居里夫人\n\nMarie Curie 发现了镭和钋，两次获得诺贝尔奖。Pierre Curie 是她的丈夫。\n",
+	}
+
+	for _, strategy := range []string{StrategyRune, StrategySentence, StrategyParagraph, StrategyToken} {
+		for _, size := range []int{3, 10, 40} {
+			c, err := New(strategy, size, size/3)
+			if err != nil {
+				t.Fatalf("New(%q): %v", strategy, err)
+			}
+			for name, text := range texts {
+				chunks := c.Chunk(text)
+				if len(chunks) == 0 {
+					t.Fatalf("%s size %d %s: no chunks", strategy, size, name)
+				}
+				for i, chunk := range chunks {
+					if chunk.Start < 0 || chunk.End > len(text) || chunk.Start >= chunk.End {
+						t.Fatalf("%s size %d %s chunk %d: invalid span [%d, %d)", strategy, size, name, i, chunk.Start, chunk.End)
+					}
+					raw := text[chunk.Start:chunk.End]
+					if !utf8.ValidString(raw) || !utf8.ValidString(chunk.Text) {
+						t.Errorf("%s size %d %s chunk %d: cuts a character: %q", strategy, size, name, i, raw)
+					}
+					if got := utils.CleanText(raw); got != chunk.Text {
+						t.Errorf("%s size %d %s chunk %d: span text %q, want %q", strategy, size, name, i, got, chunk.Text)
+					}
+					if i > 0 && chunk.Start < chunks[i-1].Start {
+						t.Errorf("%s size %d %s chunk %d: chunks out of order", strategy, size, name, i)
+					}
+				}
+			}
+		}
+	}
+}
+
+// TestNewUnknownStrategy 未知的策略名称返回错误
+func TestNewUnknownStrategy(t *testing.T) {
+	if _, err := New("semantic", 100, 10); err == nil {
+		t.Fatal("expected error for unknown strategy")
+	}
+}
+
+// coveredTokens 返回与块的字节区间相交的 token 下标（文本已清理时原始偏移即分词偏移）
+func coveredTokens(tokens []Token, chunk utils.TextChunk) []int {
+	var covered []int
+	for i, token := range tokens {
+		if token.End > chunk.Start && token.Start < chunk.End {
+			covered = append(covered, i)
+		}
+	}
+	return covered
+}
+
+// sharedTokens 两个升序下标列表的公共元素个数
+func sharedTokens(a, b []int) int {
+	count := 0
+	for _, i := range a {
+		for _, j := range b {
+			if i == j {
+				count++
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,111 @@
+package chunker
+
+// paragraph.go - 按段落 / Markdown 标题分块
+// 用途：保留文档结构，尽量让一个块对应一个完整的段落或章节
+// 主要功能：
+// - ParagraphChunker: 以空行分隔段落，以 Markdown 标题（行首的 #）分隔章节
+// - 同一章节内的连续段落合并成不超过 size 个字符的块，块不会跨越章节
+// - 超过 size 的段落按句子切分，超过 size 的句子按字符切分
+
+import (
+	"strings"
+
+	"github.com/example/go-scaffold/pkg/utils"
+)
+
+// ParagraphChunker 按段落 / Markdown 标题分块
+type ParagraphChunker struct {
+	size    int
+	overlap int
+}
+
+// NewParagraphChunker 创建段落分块器
+// size: 每块最大字符数；overlap: 相邻块重叠的最大字符数（以整段 / 整句为单位）
+func NewParagraphChunker(size, overlap int) *ParagraphChunker {
+	return &ParagraphChunker{size: size, overlap: overlap}
+}
+
+// Chunk 切分文本
+func (c *ParagraphChunker) Chunk(text string) []utils.TextChunk {
+	d := newDocument(text)
+
+	var chunks []utils.TextChunk
+	for _, section := range d.sections() {
+		var units []span
+		for _, paragraph := range section {
+			if c.size > 0 && d.runeLen(paragraph.start, paragraph.end) > c.size {
+				units = append(units, d.sentences(paragraph)...)
+			} else {
+				units = append(units, paragraph)
+			}
+		}
+		chunks = append(chunks, d.pack(units, c.size, c.overlap)...)
+	}
+	return chunks
+}
+
+// sections 按 Markdown 标题切分章节，每个章节是段落列表（标题行单独作为一个段落）
+func (d *document) sections() [][]span {
+	var sections [][]span
+	var section []span
+	var paragraph []span // 当前段落的行
+
+	endParagraph := func() {
+		if len(paragraph) > 0 {
+			section = append(section, span{paragraph[0].start, paragraph[len(paragraph)-1].end})
+			paragraph = nil
+		}
+	}
+
+	for _, line := range d.lines() {
+		if isHeading(d.cleaned[line.start:line.end]) {
+			endParagraph()
+			if len(section) > 0 {
+				sections = append(sections, section)
+				section = nil
+			}
+			section = append(section, line)
+			continue
+		}
+
+		if d.blankLineBefore(line.start) {
+			endParagraph()
+		}
+		paragraph = append(paragraph, line)
+	}
+	endParagraph()
+	if len(section) > 0 {
+		sections = append(sections, section)
+	}
+
+	return sections
+}
+
+// isHeading 判断是否为 Markdown ATX 标题行（1 到 6 个 # 后跟空格）
+func isHeading(line string) bool {
+	level := len(line) - len(strings.TrimLeft(line, "#"))
+	return level >= 1 && level <= 6 && strings.HasPrefix(line[level:], " ")
+}
+
+// lines 按原始文本中的换行切分（清理后文本中的区间，不含行间的空格）
+func (d *document) lines() []span {
+	var lines []span
+	start := 0
+	for i := 1; i < len(d.cleaned); i++ {
+		if d.cleaned[i-1] == ' ' && strings.Contains(d.gap(i), "\n") {
+			lines = appendSpan(lines, d.cleaned, start, i)
+			start = i
+		}
+	}
+	return appendSpan(lines, d.cleaned, start, len(d.cleaned))
+}
+
+// blankLineBefore 判断位置 i 之前被合并的空白中是否有空行
+func (d *document) blankLineBefore(i int) bool {
+	return i > 0 && d.cleaned[i-1] == ' ' && strings.Count(d.gap(i), "\n") >= 2
+}
+
+// gap 返回清理后位置 i 之前的空格在原始文本中对应的空白序列（i-1 需为合并后的空格）
+func (d *document) gap(i int) string {
+	return d.raw[d.offsets[i-1]:d.offsets[i]]
+}
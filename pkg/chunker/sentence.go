@@ -0,0 +1,92 @@
+package chunker
+
+// sentence.go - 按句子分块
+// 用途：在句子边界处切分，避免一句话被拆到两个块中
+// 主要功能：
+// - SentenceChunker: 识别中文句末标点（。！？…）和英文句末标点（.!? 后跟空白），
+//   把连续的句子合并成不超过 size 个字符的块，相邻块以整句重叠
+// - 句末标点后的右引号、右括号归入当前句子
+// - 超过 size 的长句按字符切分
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/example/go-scaffold/pkg/utils"
+)
+
+// SentenceChunker 按句子分块
+type SentenceChunker struct {
+	size    int
+	overlap int
+}
+
+// NewSentenceChunker 创建句子分块器
+// size: 每块最大字符数；overlap: 相邻块重叠的最大字符数（以整句为单位）
+func NewSentenceChunker(size, overlap int) *SentenceChunker {
+	return &SentenceChunker{size: size, overlap: overlap}
+}
+
+// Chunk 切分文本
+func (c *SentenceChunker) Chunk(text string) []utils.TextChunk {
+	d := newDocument(text)
+	return d.pack(d.sentences(span{0, len(d.cleaned)}), c.size, c.overlap)
+}
+
+// 句末标点：中文标点总是断句，英文标点后面需要跟空白或文本结束（避免把 3.14、e.g. 中间断开）
+const (
+	cjkTerminators   = "。！？…"
+	asciiTerminators = ".!?"
+	// closingMarks 句末标点之后仍属于当前句子的右引号和右括号
+	closingMarks = "”’」』）)》\"'"
+)
+
+// sentences 把区间切分成句子（不含句子之间的空格）
+func (d *document) sentences(s span) []span {
+	text := d.cleaned
+	var result []span
+
+	start := s.start
+	for i := s.start; i < s.end; {
+		r, width := utf8.DecodeRuneInString(text[i:])
+		i += width
+
+		if !strings.ContainsRune(cjkTerminators, r) && !strings.ContainsRune(asciiTerminators, r) {
+			continue
+		}
+
+		// 连续的句末标点和其后的右引号 / 右括号
+		for i < s.end {
+			next, nextWidth := utf8.DecodeRuneInString(text[i:])
+			if !strings.ContainsRune(cjkTerminators, next) && !strings.ContainsRune(asciiTerminators, next) &&
+				!strings.ContainsRune(closingMarks, next) {
+				break
+			}
+			i += nextWidth
+		}
+
+		if strings.ContainsRune(asciiTerminators, r) && i < s.end && text[i] != ' ' {
+			continue
+		}
+
+		result = appendSpan(result, text, start, i)
+		start = i
+	}
+	result = appendSpan(result, text, start, s.end)
+
+	return result
+}
+
+// appendSpan 去掉首尾空格后追加非空区间
+func appendSpan(spans []span, text string, start, end int) []span {
+	for start < end && text[start] == ' ' {
+		start++
+	}
+	for end > start && text[end-1] == ' ' {
+		end--
+	}
+	if start < end {
+		spans = append(spans, span{start, end})
+	}
+	return spans
+}
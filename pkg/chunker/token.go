@@ -0,0 +1,90 @@
+package chunker
+
+// token.go - 按 token 数分块
+// 用途：块大小和重叠按 token 计算，与 embedding / LLM 模型的 token 限制一致，中英文文本的重叠长度也更一致
+// 主要功能：
+// - TokenChunker: 每块不超过 size 个 token，相邻块重叠不超过 overlap 个 token
+// - 块只在字符边界处切分：token 落在多字节字符内部时（字节级 BPE），块边界退到字符边界，块只会变小
+// - 分词器可替换（Tokenizer 接口），默认使用离线近似分词器 ApproxTokenizer
+
+import (
+	"unicode/utf8"
+
+	"github.com/example/go-scaffold/pkg/utils"
+)
+
+// TokenChunker 按 token 数分块
+type TokenChunker struct {
+	tokenizer Tokenizer
+	size      int
+	overlap   int
+}
+
+// NewTokenChunker 创建 token 分块器
+// tokenizer: 分词器，nil 时使用 ApproxTokenizer；size: 每块 token 数；overlap: 相邻块重叠的 token 数
+func NewTokenChunker(tokenizer Tokenizer, size, overlap int) *TokenChunker {
+	if tokenizer == nil {
+		tokenizer = NewApproxTokenizer()
+	}
+	return &TokenChunker{tokenizer: tokenizer, size: size, overlap: overlap}
+}
+
+// Chunk 切分文本（在清理空白后的文本上分词）
+func (c *TokenChunker) Chunk(text string) []utils.TextChunk {
+	d := newDocument(text)
+	tokens := c.tokenizer.Tokenize(d.cleaned)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	if c.size <= 0 || len(tokens) <= c.size {
+		chunk, ok := d.chunk(tokens[0].Start, tokens[len(tokens)-1].End)
+		if !ok {
+			return nil
+		}
+		return []utils.TextChunk{chunk}
+	}
+
+	// 可以切分的 token 下标：token 从字符边界开始（最后一个元素为 len(tokens)）
+	cuts := make([]int, 0, len(tokens)+1)
+	for i, token := range tokens {
+		if utf8.RuneStart(d.cleaned[token.Start]) {
+			cuts = append(cuts, i)
+		}
+	}
+	cuts = append(cuts, len(tokens))
+
+	overlap := c.overlap
+	if overlap < 0 || overlap >= c.size {
+		// 重叠不小于块大小时不重叠，避免死循环
+		overlap = 0
+	}
+
+	// 每块取不超过 size 个 token 的最长区间，下一块从距块尾不超过 overlap 个 token 的最早切分点开始
+	// 分词器的 token 都在字符边界上时，块大小和重叠都恰好是 size 和 overlap
+	var chunks []utils.TextChunk
+	for k := 0; k < len(cuts)-1; {
+		start := cuts[k]
+		e := k + 1
+		for e+1 < len(cuts) && cuts[e+1]-start <= c.size {
+			e++
+		}
+		// cuts[k+1] - start > size 时是单个字符的 token 数超过 size，整个字符作为一块
+
+		end := cuts[e]
+		if chunk, ok := d.chunk(tokens[start].Start, tokens[end-1].End); ok {
+			chunks = append(chunks, chunk)
+		}
+		if end == len(tokens) {
+			break
+		}
+
+		next := e
+		for next > k+1 && cuts[next-1] >= end-overlap {
+			next--
+		}
+		k = next
+	}
+
+	return chunks
+}
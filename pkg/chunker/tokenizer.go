@@ -0,0 +1,141 @@
+package chunker
+
+// tokenizer.go - 分词器接口与离线近似分词器
+// 用途：按 token 计算块大小，使块大小与 embedding / LLM 模型的上下文限制对应
+// 主要功能：
+// - Tokenizer 接口：返回每个 token 在文本中的字节区间，可接入模型真实的分词器
+// - ApproxTokenizer: 模仿 GPT 系列 BPE 分词规律的近似分词器，无需词表、离线可用
+//
+// 近似规则：
+// - 非 ASCII 字符（中日韩文字、全角标点、带重音字母等）每个 UTF-8 字节一个 token：
+//   字节级 BPE（GPT 系列的 cl100k、o200k 等）每个字节最多一个 token，因此这部分是严格上限（中文通常多估 1~2 倍）
+// - 英文单词连同前导空格为一个 token，超过 6 个字母的单词每 4 个字母一个 token
+// - 数字每 3 位一个 token
+// - ASCII 标点符号和其他符号每个一个 token，连续空白为一个 token
+//
+// 中文等非 ASCII 文本按它切分的块不会超出模型的 token 限制；英文按常见 BPE 词表的规律估算（通常略多估），
+// 由罕见字母组合构成的英文文本需要严格上限时实现 Tokenizer 接入模型真实的分词器
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token token 在文本中的字节区间 [Start, End)
+type Token struct {
+	Start int
+	End   int
+}
+
+// Tokenizer 分词器
+type Tokenizer interface {
+	// Tokenize 切分文本，返回按位置排序、互不重叠的 token 区间
+	// 区间可以落在多字节字符内部（字节级 BPE），TokenChunker 只在字符边界处切分
+	Tokenize(text string) []Token
+}
+
+// ApproxTokenizer 离线近似 BPE 分词器
+type ApproxTokenizer struct{}
+
+// NewApproxTokenizer 创建近似分词器
+func NewApproxTokenizer() *ApproxTokenizer {
+	return &ApproxTokenizer{}
+}
+
+// 字符类别
+const (
+	classSpace = iota
+	classLetter
+	classDigit
+	classOther
+	classNonASCII
+)
+
+// Tokenize 切分文本
+// 非 ASCII 字符的每个字节是一个 token，因此 token 区间可能落在多字节字符内部（与字节级 BPE 相同）
+func (t *ApproxTokenizer) Tokenize(text string) []Token {
+	var tokens []Token
+
+	for i := 0; i < len(text); {
+		r, width := utf8.DecodeRuneInString(text[i:])
+		class := charClass(r)
+		start := i
+
+		switch class {
+		case classNonASCII:
+			for j := 0; j < width; j++ {
+				tokens = append(tokens, Token{i + j, i + j + 1})
+			}
+			i += width
+			continue
+		case classOther:
+			i += width
+			tokens = append(tokens, Token{start, i})
+			continue
+		case classSpace:
+			end := scanClass(text, i, classSpace)
+			// 单个空格与后面的单词合并（GPT 分词器中 " word" 是一个 token）
+			if end-i == 1 && text[i] == ' ' && end < len(text) && charClass(rune(text[end])) == classLetter {
+				i = end
+				class = classLetter
+				break
+			}
+			tokens = append(tokens, Token{start, end})
+			i = end
+			continue
+		}
+
+		// 字母和数字：先取同类字符的连续序列，再按规则拆分
+		end := scanClass(text, i, class)
+		size := 3 // 每个 token 的字符数
+		if class == classLetter {
+			size = 4
+			if end-i <= 6 {
+				size = 6
+			}
+		}
+		tokens = appendPieces(tokens, start, i, end, size)
+		i = end
+	}
+
+	return tokens
+}
+
+// appendPieces 把 [wordStart, end) 每 size 个字符拆成一个 token，第一个 token 从 start 开始（包含前导空格）
+// 字母和数字都是 ASCII 字符，字符数即字节数
+func appendPieces(tokens []Token, start, wordStart, end, size int) []Token {
+	for j := wordStart; j < end; {
+		j = min(j+size, end)
+		tokens = append(tokens, Token{start, j})
+		start = j
+	}
+	return tokens
+}
+
+// scanClass 返回从 i 开始同类字符序列的结束位置
+func scanClass(text string, i, class int) int {
+	for i < len(text) {
+		r, width := utf8.DecodeRuneInString(text[i:])
+		if charClass(r) != class {
+			break
+		}
+		i += width
+	}
+	return i
+}
+
+// charClass 返回字符类别
+func charClass(r rune) int {
+	switch {
+	case r >= utf8.RuneSelf:
+		return classNonASCII
+	case unicode.IsSpace(r):
+		return classSpace
+	case unicode.IsLetter(r):
+		return classLetter
+	case unicode.IsDigit(r):
+		return classDigit
+	default:
+		return classOther
+	}
+}
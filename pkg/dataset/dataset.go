@@ -0,0 +1,155 @@
+package dataset
+
+// dataset.go - 多跳问答评测数据集
+// 用途：从本地文件加载 HotpotQA、2WikiMultiHopQA、MuSiQue 格式的数据集，得到语料和带标准答案的问题
+// 主要功能：
+// - Dataset: 语料（去重后的段落，可直接用于 HippoRAG.Insert）和问题列表
+// - Sample: 问题、答案（含别名）、支撑段落的文档 ID
+// - Load: 按格式加载文件（JSON 数组或 JSONL 均可），limit 限制问题数量（语料只包含这些问题的段落）
+// - 各格式的解析见 hotpotqa.go（HotpotQA / 2WikiMultiHopQA）和 musique.go
+//
+// 段落正文为 "标题\n段落"，文档 ID 由标题和段落内容生成，同一段落在多个问题中出现时只保留一份
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/example/go-scaffold/pkg/document"
+	"github.com/example/go-scaffold/pkg/utils"
+)
+
+// Format 数据集格式
+type Format string
+
+const (
+	FormatHotpotQA Format = "hotpotqa"
+	Format2Wiki    Format = "2wikimultihopqa"
+	FormatMuSiQue  Format = "musique"
+)
+
+// Sample 一个问题
+type Sample struct {
+	ID         string   // 问题 ID
+	Question   string   // 问题
+	Answers    []string // 标准答案，第一个为主答案，其余为别名（测试集可能为空）
+	Supporting []string // 支撑段落的文档 ID（对应 Dataset.Corpus 中的文档）
+	Type       string   // 问题类型（如 bridge / comparison、2hop / 3hop）
+}
+
+// Dataset 数据集
+type Dataset struct {
+	Format  Format
+	Corpus  []document.Document // 所有问题的段落（去重）
+	Samples []Sample
+}
+
+// Load 从本地文件加载数据集
+// limit: 最多加载的问题数，<= 0 时加载全部
+func Load(format Format, path string, limit int) (*Dataset, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+
+	var ds *Dataset
+	switch format {
+	case FormatHotpotQA:
+		ds, err = ParseHotpotQA(f, limit)
+	case Format2Wiki:
+		ds, err = Parse2Wiki(f, limit)
+	case FormatMuSiQue:
+		ds, err = ParseMuSiQue(f, limit)
+	default:
+		return nil, fmt.Errorf("unknown dataset format: %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return ds, nil
+}
+
+// corpusBuilder 构建去重后的语料
+type corpusBuilder struct {
+	docs  []document.Document
+	index map[string]bool
+}
+
+func newCorpusBuilder() *corpusBuilder {
+	return &corpusBuilder{index: make(map[string]bool)}
+}
+
+// add 添加段落，返回文档 ID
+func (b *corpusBuilder) add(title, text string) string {
+	content := title + "\n" + text
+	id := "passage-" + utils.Hash(content)[:16]
+	if !b.index[id] {
+		b.index[id] = true
+		b.docs = append(b.docs, document.Document{
+			ID:       id,
+			Text:     content,
+			Metadata: map[string]string{document.MetaTitle: title},
+		})
+	}
+	return id
+}
+
+// decodeRecords 逐条解码 JSON 数组或 JSONL 中的记录，fn 返回 false 时停止
+func decodeRecords(r io.Reader, fn func(raw json.RawMessage) (bool, error)) error {
+	reader := bufio.NewReader(r)
+
+	// 跳过开头的空白和 BOM，判断是 JSON 数组还是 JSONL
+	isArray := false
+	for {
+		b, err := reader.Peek(1)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read: %w", err)
+		}
+		if b[0] == ' ' || b[0] == '\t' || b[0] == '\r' || b[0] == '\n' {
+			reader.ReadByte()
+			continue
+		}
+		if bom, _ := reader.Peek(3); string(bom) == "\ufeff" {
+			reader.Discard(3)
+			continue
+		}
+		isArray = b[0] == '['
+		break
+	}
+
+	dec := json.NewDecoder(reader)
+	if isArray {
+		if _, err := dec.Token(); err != nil {
+			return fmt.Errorf("decode: %w", err)
+		}
+	}
+
+	for n := 1; ; n++ {
+		if isArray && !dec.More() {
+			return nil
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if !isArray && errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("decode record %d: %w", n, err)
+		}
+
+		more, err := fn(raw)
+		if err != nil {
+			return fmt.Errorf("record %d: %w", n, err)
+		}
+		if !more {
+			return nil
+		}
+	}
+}
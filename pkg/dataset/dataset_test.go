@@ -0,0 +1,77 @@
+package dataset
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/example/go-scaffold/pkg/document"
+)
+
+// TestLoadSamples 加载 testdata 中各格式的示例文件，检查问题、答案和支撑段落
+func TestLoadSamples(t *testing.T) {
+	tests := []struct {
+		format      Format
+		file        string
+		samples     int
+		corpus      int // 去重后的段落数
+		firstType   string
+		firstAnswer string
+		supporting  []string // 第一个问题的支撑段落标题
+	}{
+		{FormatHotpotQA, "hotpotqa_sample.json", 2, 4, "comparison", "yes", []string{"Ed Wood", "Scott Derrickson"}},
+		{Format2Wiki, "2wikimultihopqa_sample.json", 1, 3, "compositional", "Małgorzata Braunek", []string{"Polish-Russian War (film)", "Xawery Żuławski"}},
+		{FormatMuSiQue, "musique_sample.jsonl", 2, 4, "2hop", "June 1982", []string{"Lionel Messi", "Diego Maradona"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			ds, err := Load(tt.format, filepath.Join("testdata", tt.file), 0)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if len(ds.Samples) != tt.samples || len(ds.Corpus) != tt.corpus {
+				t.Fatalf("got %d samples and %d passages, want %d and %d",
+					len(ds.Samples), len(ds.Corpus), tt.samples, tt.corpus)
+			}
+
+			first := ds.Samples[0]
+			if first.Type != tt.firstType || len(first.Answers) == 0 || first.Answers[0] != tt.firstAnswer {
+				t.Errorf("first sample: type %q, answers %q", first.Type, first.Answers)
+			}
+
+			titles := make(map[string]string) // 文档 ID -> 标题
+			for _, doc := range ds.Corpus {
+				titles[doc.ID] = doc.Metadata[document.MetaTitle]
+				if !strings.HasPrefix(doc.Text, titles[doc.ID]+"\n") {
+					t.Errorf("passage %s does not start with its title: %q", doc.ID, doc.Text)
+				}
+			}
+			var supporting []string
+			for _, id := range first.Supporting {
+				supporting = append(supporting, titles[id])
+			}
+			if strings.Join(supporting, "|") != strings.Join(tt.supporting, "|") {
+				t.Errorf("supporting passages: got %q, want %q", supporting, tt.supporting)
+			}
+
+			limited, err := Load(tt.format, filepath.Join("testdata", tt.file), 1)
+			if err != nil {
+				t.Fatalf("Load with limit: %v", err)
+			}
+			if len(limited.Samples) != 1 {
+				t.Errorf("limit 1: got %d samples", len(limited.Samples))
+			}
+		})
+	}
+}
+
+// TestJoinSentences HotpotQA 的句子自带前导空格，2WikiMultiHopQA 的没有
+func TestJoinSentences(t *testing.T) {
+	if got := joinSentences([]string{"A b.", " C d."}); got != "A b. C d." {
+		t.Errorf("leading space: got %q", got)
+	}
+	if got := joinSentences([]string{"A b.", "C d."}); got != "A b. C d." {
+		t.Errorf("no leading space: got %q", got)
+	}
+}
@@ -0,0 +1,121 @@
+package dataset
+
+// hotpotqa.go - HotpotQA / 2WikiMultiHopQA 格式
+// 用途：两个数据集的 JSON 结构相同：
+//
+//	{"_id": "...", "question": "...", "answer": "...", "type": "...",
+//	 "context": [[标题, [句子, ...]], ...], "supporting_facts": [[标题, 句子下标], ...]}
+//
+// 每个 context 条目是一个段落，supporting_facts 中出现的标题对应的段落为支撑段落
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ParseHotpotQA 解析 HotpotQA 数据集（如 hotpot_dev_distractor_v1.json）
+func ParseHotpotQA(r io.Reader, limit int) (*Dataset, error) {
+	return parseWikiFormat(r, limit, FormatHotpotQA)
+}
+
+// Parse2Wiki 解析 2WikiMultiHopQA 数据集（如 dev.json）
+func Parse2Wiki(r io.Reader, limit int) (*Dataset, error) {
+	return parseWikiFormat(r, limit, Format2Wiki)
+}
+
+// wikiRecord HotpotQA / 2WikiMultiHopQA 的一条记录
+type wikiRecord struct {
+	ID              string              `json:"_id"`
+	Question        string              `json:"question"`
+	Answer          string              `json:"answer"`
+	Type            string              `json:"type"`
+	Context         [][]json.RawMessage `json:"context"`
+	SupportingFacts [][]json.RawMessage `json:"supporting_facts"`
+}
+
+func parseWikiFormat(r io.Reader, limit int, format Format) (*Dataset, error) {
+	corpus := newCorpusBuilder()
+	ds := &Dataset{Format: format}
+
+	err := decodeRecords(r, func(raw json.RawMessage) (bool, error) {
+		var record wikiRecord
+		if err := json.Unmarshal(raw, &record); err != nil {
+			return false, err
+		}
+
+		supportingTitles := make(map[string]bool)
+		for _, fact := range record.SupportingFacts {
+			if len(fact) == 0 {
+				continue
+			}
+			var title string
+			if err := json.Unmarshal(fact[0], &title); err != nil {
+				return false, fmt.Errorf("supporting fact title: %w", err)
+			}
+			supportingTitles[title] = true
+		}
+
+		sample := Sample{ID: record.ID, Question: record.Question, Type: record.Type}
+		if record.Answer != "" {
+			sample.Answers = []string{record.Answer}
+		}
+		for _, entry := range record.Context {
+			if len(entry) != 2 {
+				return false, fmt.Errorf("context entry has %d elements, want 2", len(entry))
+			}
+			var title string
+			var sentences []string
+			if err := json.Unmarshal(entry[0], &title); err != nil {
+				return false, fmt.Errorf("context title: %w", err)
+			}
+			if err := json.Unmarshal(entry[1], &sentences); err != nil {
+				return false, fmt.Errorf("context sentences: %w", err)
+			}
+
+			id := corpus.add(title, joinSentences(sentences))
+			if supportingTitles[title] && !containsString(sample.Supporting, id) {
+				sample.Supporting = append(sample.Supporting, id)
+			}
+		}
+
+		ds.Samples = append(ds.Samples, sample)
+		return limit <= 0 || len(ds.Samples) < limit, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ds.Corpus = corpus.docs
+	return ds, nil
+}
+
+// joinSentences 拼接句子：HotpotQA 的句子自带前导空格，2WikiMultiHopQA 的句子没有，需要补空格
+func joinSentences(sentences []string) string {
+	var sb strings.Builder
+	for i, sentence := range sentences {
+		if i > 0 && sentence != "" {
+			first, _ := utf8.DecodeRuneInString(sentence)
+			prev := sentences[i-1]
+			last, _ := utf8.DecodeLastRuneInString(prev)
+			if prev != "" && !unicode.IsSpace(first) && !unicode.IsSpace(last) {
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteString(sentence)
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// containsString 判断切片中是否包含 s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
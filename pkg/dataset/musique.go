@@ -0,0 +1,64 @@
+package dataset
+
+// musique.go - MuSiQue 格式
+// 用途：MuSiQue 每行一条记录（JSONL）：
+//
+//	{"id": "2hop__...", "question": "...", "answer": "...", "answer_aliases": [...],
+//	 "paragraphs": [{"idx": 0, "title": "...", "paragraph_text": "...", "is_supporting": true}, ...]}
+//
+// is_supporting 为 true 的段落为支撑段落，问题类型取 ID 中 "__" 之前的部分（如 2hop、3hop1）
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// musiqueRecord MuSiQue 的一条记录
+type musiqueRecord struct {
+	ID            string   `json:"id"`
+	Question      string   `json:"question"`
+	Answer        string   `json:"answer"`
+	AnswerAliases []string `json:"answer_aliases"`
+	Paragraphs    []struct {
+		Title        string `json:"title"`
+		Text         string `json:"paragraph_text"`
+		IsSupporting bool   `json:"is_supporting"`
+	} `json:"paragraphs"`
+}
+
+// ParseMuSiQue 解析 MuSiQue 数据集（如 musique_ans_v1.0_dev.jsonl）
+func ParseMuSiQue(r io.Reader, limit int) (*Dataset, error) {
+	corpus := newCorpusBuilder()
+	ds := &Dataset{Format: FormatMuSiQue}
+
+	err := decodeRecords(r, func(raw json.RawMessage) (bool, error) {
+		var record musiqueRecord
+		if err := json.Unmarshal(raw, &record); err != nil {
+			return false, err
+		}
+
+		sample := Sample{ID: record.ID, Question: record.Question}
+		if i := strings.Index(record.ID, "__"); i > 0 {
+			sample.Type = record.ID[:i]
+		}
+		if record.Answer != "" {
+			sample.Answers = append([]string{record.Answer}, record.AnswerAliases...)
+		}
+		for _, paragraph := range record.Paragraphs {
+			id := corpus.add(paragraph.Title, strings.TrimSpace(paragraph.Text))
+			if paragraph.IsSupporting && !containsString(sample.Supporting, id) {
+				sample.Supporting = append(sample.Supporting, id)
+			}
+		}
+
+		ds.Samples = append(ds.Samples, sample)
+		return limit <= 0 || len(ds.Samples) < limit, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ds.Corpus = corpus.docs
+	return ds, nil
+}
@@ -0,0 +1,49 @@
+package document
+
+// document.go - 文档
+// 用途：索引的输入单位，携带调用方指定的文档 ID 和元数据
+// 主要功能：
+// - Document: 文档 ID、文本、元数据（标题、URL、时间戳等）
+// - FromTexts: 把纯文本列表转换为文档（ID 留空，由索引方按内容生成）
+// - ID: 按内容生成的文档 ID
+
+import "github.com/example/go-scaffold/pkg/utils"
+
+// 常用元数据键
+const (
+	MetaTitle     = "title"      // 标题
+	MetaURL       = "url"        // 来源链接
+	MetaSource    = "source"     // 来源（如文件路径）
+	MetaCreatedAt = "created_at" // 创建时间（RFC 3339）
+	MetaUpdatedAt = "updated_at" // 更新时间（RFC 3339）
+)
+
+// Document 文档
+type Document struct {
+	ID       string            `json:"id"`                 // 文档 ID，为空时由索引方按内容生成
+	Text     string            `json:"text"`               // 文档正文
+	Metadata map[string]string `json:"metadata,omitempty"` // 可选元数据
+}
+
+// ID 返回文档正文对应的文档 ID（基于内容哈希），调用方未指定文档 ID 时使用
+func ID(text string) string {
+	return "doc-" + utils.Hash(text)[:16]
+}
+
+// FromTexts 把纯文本列表转换为文档列表
+func FromTexts(texts []string) []Document {
+	docs := make([]Document, len(texts))
+	for i, text := range texts {
+		docs[i] = Document{Text: text}
+	}
+	return docs
+}
+
+// Texts 返回文档正文列表
+func Texts(docs []Document) []string {
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		texts[i] = doc.Text
+	}
+	return texts
+}
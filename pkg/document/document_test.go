@@ -0,0 +1,42 @@
+package document
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestFromTexts 纯文本转换为 ID 留空的文档，Texts 还原出相同的文本列表
+func TestFromTexts(t *testing.T) {
+	texts := []string{"爱因斯坦提出了相对论。", "居里夫人发现了镭。"}
+
+	docs := FromTexts(texts)
+	if len(docs) != len(texts) {
+		t.Fatalf("FromTexts returned %d documents, want %d", len(docs), len(texts))
+	}
+	for i, doc := range docs {
+		if doc.ID != "" || doc.Text != texts[i] || doc.Metadata != nil {
+			t.Errorf("docs[%d] = %+v", i, doc)
+		}
+	}
+	if got := Texts(docs); !reflect.DeepEqual(got, texts) {
+		t.Errorf("Texts = %v, want %v", got, texts)
+	}
+	if got := Texts(nil); len(got) != 0 {
+		t.Errorf("Texts(nil) = %v", got)
+	}
+}
+
+// TestID 文档 ID 只由正文决定
+func TestID(t *testing.T) {
+	id := ID("爱因斯坦提出了相对论。")
+	if !strings.HasPrefix(id, "doc-") || len(id) != len("doc-")+16 {
+		t.Errorf("ID = %q, want doc- followed by 16 hex digits", id)
+	}
+	if ID("爱因斯坦提出了相对论。") != id {
+		t.Error("ID changed for the same text")
+	}
+	if ID("居里夫人发现了镭。") == id {
+		t.Error("different texts share an ID")
+	}
+}
@@ -0,0 +1,118 @@
+package embedding
+
+// batch.go - Embedding 请求批量拆分
+// 用途：大量文本向量化时，按条数和估算 token 数把输入拆成多个请求，避免超过服务端单次请求上限
+// 主要功能：
+// - SetBatching: 设置每个请求的最大条数、最大 token 数和并发请求数
+// - splitBatches: 按顺序拆分输入，单条超过 token 上限的文本单独成一批
+// - embedBatches: 并发请求各批次，结果按原始下标写回
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/example/go-scaffold/pkg/utils"
+)
+
+// 默认批量参数（OpenAI 单次请求最多 2048 条输入，总 token 上限约 30 万）
+const (
+	defaultMaxBatchSize     = 2048
+	defaultMaxBatchTokens   = 250000
+	defaultBatchConcurrency = 4
+)
+
+// batch 一个请求批次
+type batch struct {
+	offset int      // 第一条文本在原始输入中的下标
+	texts  []string // 批次内的文本
+	tokens int      // 估算 token 数
+}
+
+// SetBatching 设置批量请求参数
+// maxItems: 每个请求的最大文本数，maxTokens: 每个请求的最大估算 token 数，concurrency: 同时进行的请求数
+// 参数 <= 0 时使用默认值
+func (c *OpenAIClient) SetBatching(maxItems, maxTokens, concurrency int) {
+	if maxItems <= 0 {
+		maxItems = defaultMaxBatchSize
+	}
+	if maxTokens <= 0 {
+		maxTokens = defaultMaxBatchTokens
+	}
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+
+	c.maxBatchSize = maxItems
+	c.maxBatchTokens = maxTokens
+	c.concurrency = concurrency
+}
+
+// splitBatches 按顺序把 texts 拆成批次，每批不超过 maxItems 条、maxTokens 个估算 token
+func splitBatches(texts []string, maxItems, maxTokens int) []batch {
+	var batches []batch
+	current := batch{}
+
+	for i, text := range texts {
+		tokens := utils.EstimateTokens(text)
+		full := len(current.texts) >= maxItems || current.tokens+tokens > maxTokens
+		if len(current.texts) > 0 && full {
+			batches = append(batches, current)
+			current = batch{offset: i}
+		}
+		current.texts = append(current.texts, text)
+		current.tokens += tokens
+	}
+	if len(current.texts) > 0 {
+		batches = append(batches, current)
+	}
+
+	return batches
+}
+
+// embedBatches 并发请求所有批次，结果写入 result 的对应位置
+// 任一批次失败时取消其余请求并返回第一个错误
+func (c *OpenAIClient) embedBatches(ctx context.Context, batches []batch, result [][]float64) error {
+	if len(batches) == 1 {
+		return c.embedBatch(ctx, batches[0], result)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		firstErr error
+		errOnce  sync.Once
+	)
+	sem := make(chan struct{}, max(c.concurrency, 1))
+
+	for i, b := range batches {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, b batch) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := c.embedBatch(ctx, b, result); err != nil {
+				errOnce.Do(func() {
+					firstErr = fmt.Errorf("embed batch %d/%d: %w", i+1, len(batches), err)
+					cancel()
+				})
+			}
+		}(i, b)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
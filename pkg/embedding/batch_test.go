@@ -0,0 +1,202 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/example/go-scaffold/pkg/retry"
+)
+
+// TestSplitBatches 按条数和 token 数拆分，单条超限的文本单独成一批
+func TestSplitBatches(t *testing.T) {
+	long := strings.Repeat("长", 50) // 50 个估算 token
+
+	tests := []struct {
+		name      string
+		texts     []string
+		maxItems  int
+		maxTokens int
+		want      [][]string
+	}{
+		{
+			name:      "empty",
+			maxItems:  2,
+			maxTokens: 100,
+			want:      nil,
+		},
+		{
+			name:      "by count",
+			texts:     []string{"a", "b", "c", "d", "e"},
+			maxItems:  2,
+			maxTokens: 100,
+			want:      [][]string{{"a", "b"}, {"c", "d"}, {"e"}},
+		},
+		{
+			name:      "by tokens",
+			texts:     []string{"一二三", "四五", "六七八九", "十"},
+			maxItems:  10,
+			maxTokens: 5,
+			want:      [][]string{{"一二三", "四五"}, {"六七八九", "十"}},
+		},
+		{
+			name:      "oversized item",
+			texts:     []string{"a", long, "b", "c"},
+			maxItems:  10,
+			maxTokens: 10,
+			want:      [][]string{{"a"}, {long}, {"b", "c"}},
+		},
+		{
+			name:      "oversized first item",
+			texts:     []string{long, "a"},
+			maxItems:  10,
+			maxTokens: 10,
+			want:      [][]string{{long}, {"a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batches := splitBatches(tt.texts, tt.maxItems, tt.maxTokens)
+			if len(batches) != len(tt.want) {
+				t.Fatalf("got %d batches, want %d", len(batches), len(tt.want))
+			}
+			offset := 0
+			for i, b := range batches {
+				if b.offset != offset || strings.Join(b.texts, "|") != strings.Join(tt.want[i], "|") {
+					t.Errorf("batch %d = offset %d %q, want offset %d %q", i, b.offset, b.texts, offset, tt.want[i])
+				}
+				offset += len(b.texts)
+			}
+		})
+	}
+}
+
+// embeddingServer 模拟 embeddings 接口：文本 "text-N ..." 的向量为 [N]，data 倒序返回
+// 批次越靠前响应越慢，使各批次乱序完成；failOn 中的文本返回 400
+type embeddingServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests [][]string
+}
+
+func newEmbeddingServer(t *testing.T, failOn string) *embeddingServer {
+	s := &embeddingServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req embeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		s.mu.Lock()
+		s.requests = append(s.requests, req.Input)
+		s.mu.Unlock()
+
+		type item struct {
+			Embedding []float64 `json:"embedding"`
+			Index     int       `json:"index"`
+		}
+		var data []item
+		for i := len(req.Input) - 1; i >= 0; i-- {
+			if req.Input[i] == failOn {
+				http.Error(w, `{"error": {"message": "bad input"}}`, http.StatusBadRequest)
+				return
+			}
+			data = append(data, item{Embedding: []float64{float64(textNumber(req.Input[i]))}, Index: i})
+		}
+
+		time.Sleep(time.Duration(20-textNumber(req.Input[0])) * time.Millisecond)
+		json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// textNumber 解析 "text-N ..." 中的 N
+func textNumber(text string) int {
+	n, _ := strconv.Atoi(strings.TrimPrefix(strings.Fields(text)[0], "text-"))
+	return n
+}
+
+func newBatchTestClient(url string, maxItems, maxTokens int) *OpenAIClient {
+	client := NewOpenAIClient("test", "test-model")
+	client.baseURL = url
+	client.SetRetryPolicy(retry.NoRetry())
+	client.SetBatching(maxItems, maxTokens, 3)
+	return client
+}
+
+// TestOpenAIClientEmbedBatches 拆分后并发请求，结果按输入顺序返回
+func TestOpenAIClientEmbedBatches(t *testing.T) {
+	server := newEmbeddingServer(t, "")
+	client := newBatchTestClient(server.URL, 3, 1000)
+
+	texts := make([]string, 10)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("text-%d", i)
+	}
+	embeddings, err := client.Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+
+	for i, vec := range embeddings {
+		if len(vec) != 1 || vec[0] != float64(i) {
+			t.Errorf("embedding %d = %v, want [%d]", i, vec, i)
+		}
+	}
+	if len(server.requests) != 4 {
+		t.Errorf("got %d requests, want 4", len(server.requests))
+	}
+	for _, input := range server.requests {
+		if len(input) > 3 {
+			t.Errorf("request with %d inputs exceeds maxItems", len(input))
+		}
+	}
+}
+
+// TestOpenAIClientEmbedBatchesTokens 按 token 上限拆分，单条超限的文本单独请求
+func TestOpenAIClientEmbedBatchesTokens(t *testing.T) {
+	server := newEmbeddingServer(t, "")
+	client := newBatchTestClient(server.URL, 100, 4) // "text-N" 估算 2 个 token
+
+	texts := []string{"text-0", "text-1", "text-2 " + strings.Repeat("x", 40), "text-3", "text-4"}
+	embeddings, err := client.Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	for i, vec := range embeddings {
+		if vec[0] != float64(i) {
+			t.Errorf("embedding %d = %v", i, vec)
+		}
+	}
+
+	sizes := make(map[int]int)
+	for _, input := range server.requests {
+		sizes[len(input)]++
+	}
+	if len(server.requests) != 3 || sizes[2] != 2 || sizes[1] != 1 {
+		t.Errorf("requests = %q, want two pairs and the long text alone", server.requests)
+	}
+}
+
+// TestOpenAIClientEmbedBatchesError 任一批次失败时返回带批次编号的错误
+func TestOpenAIClientEmbedBatchesError(t *testing.T) {
+	server := newEmbeddingServer(t, "text-4")
+	client := newBatchTestClient(server.URL, 2, 1000)
+
+	texts := make([]string, 6)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("text-%d", i)
+	}
+	_, err := client.Embed(context.Background(), texts)
+	if err == nil || !strings.Contains(err.Error(), "embed batch 3/3") {
+		t.Fatalf("err = %v, want batch 3/3 error", err)
+	}
+}
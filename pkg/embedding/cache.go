@@ -0,0 +1,233 @@
+package embedding
+
+// cache.go - 持久化 Embedding 缓存
+// 用途：包装任意 Client，把向量缓存到本地磁盘，重启后重新索引相同语料无需再次调用 API
+// 主要功能：
+// - CachedClient: 实现 Client 接口，可用于 Store、WeaviateStore 以及查询时的 EmbedSingle
+// - 缓存键为 (模型名, utils.Hash(文本))，更换模型不会误用旧向量
+// - 缓存文件为 JSONL，每行一条记录，新向量追加写入
+// - Embed 返回的向量是副本，调用方修改不会影响缓存
+// - Stats: 命中 / 未命中统计
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/example/go-scaffold/pkg/utils"
+)
+
+// cacheEntry 缓存文件中的一行
+type cacheEntry struct {
+	Model     string    `json:"model"`
+	Hash      string    `json:"hash"`
+	Embedding []float64 `json:"embedding"`
+}
+
+// CacheStats 缓存统计
+type CacheStats struct {
+	Hits    int64 // 命中次数（按文本计）
+	Misses  int64 // 未命中次数（按文本计）
+	Entries int   // 缓存中的向量数
+}
+
+// CachedClient 带磁盘缓存的 Embedding 客户端（并发安全）
+type CachedClient struct {
+	client  Client
+	model   string
+	path    string
+	vectors map[string][]float64 // 模型名 + 内容哈希 -> 向量
+	file    *os.File             // 追加写入的缓存文件
+	stats   CacheStats
+	mu      sync.Mutex
+}
+
+// modelNamer 能报告模型名的客户端（如 OpenAIClient）
+type modelNamer interface {
+	Model() string
+}
+
+// NewCachedClient 创建带磁盘缓存的客户端
+// client: 实际调用的客户端
+// model: 模型名（缓存键的一部分），为空时从 client.Model() 获取
+// path: 缓存文件路径，不存在时自动创建
+func NewCachedClient(client Client, model, path string) (*CachedClient, error) {
+	if model == "" {
+		if namer, ok := client.(modelNamer); ok {
+			model = namer.Model()
+		}
+	}
+	if model == "" {
+		return nil, fmt.Errorf("model name is required")
+	}
+
+	c := &CachedClient{
+		client:  client,
+		model:   model,
+		path:    path,
+		vectors: make(map[string][]float64),
+	}
+	partial, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("create cache dir: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open cache file: %w", err)
+	}
+	c.file = file
+
+	if partial {
+		if _, err := file.WriteString("\n"); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("write cache: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+// load 读取缓存文件，文件不存在时为空缓存
+// 无法解析的行（如上次写入时进程中断留下的半行）会被跳过
+// 返回：文件是否以不完整的行结尾（追加前需要先补一个换行）
+func (c *CachedClient) load() (bool, error) {
+	data, err := os.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("read cache file: %w", err)
+	}
+
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var entry cacheEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			continue
+		}
+		c.vectors[cacheKey(entry.Model, entry.Hash)] = entry.Embedding
+	}
+
+	return len(data) > 0 && data[len(data)-1] != '\n', nil
+}
+
+// cacheKey 缓存键
+func cacheKey(model, hash string) string {
+	return model + "\x00" + hash
+}
+
+// Embed 批量获取文本向量，只对未缓存的文本调用底层客户端
+func (c *CachedClient) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	result := make([][]float64, len(texts))
+	hashes := make([]string, len(texts))
+
+	// 查缓存，未命中的文本去重后统一请求
+	var missTexts []string
+	missIndex := make(map[string]int) // 内容哈希 -> missTexts 下标
+
+	c.mu.Lock()
+	for i, text := range texts {
+		hashes[i] = utils.Hash(text)
+		if vec, exists := c.vectors[cacheKey(c.model, hashes[i])]; exists {
+			result[i] = cloneVector(vec)
+			c.stats.Hits++
+			continue
+		}
+		c.stats.Misses++
+		if _, exists := missIndex[hashes[i]]; !exists {
+			missIndex[hashes[i]] = len(missTexts)
+			missTexts = append(missTexts, text)
+		}
+	}
+	c.mu.Unlock()
+
+	if len(missTexts) == 0 {
+		return result, nil
+	}
+
+	// 请求期间不持有锁，允许并发调用
+	embeddings, err := c.client.Embed(ctx, missTexts)
+	if err != nil {
+		return nil, err
+	}
+	if len(embeddings) != len(missTexts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(missTexts), len(embeddings))
+	}
+
+	if err := c.store(missTexts, embeddings); err != nil {
+		return nil, err
+	}
+
+	// 重复的文本各自拿到独立的副本
+	for i := range texts {
+		if result[i] == nil {
+			result[i] = cloneVector(embeddings[missIndex[hashes[i]]])
+		}
+	}
+
+	return result, nil
+}
+
+// store 保存新向量的副本到内存和缓存文件
+func (c *CachedClient) store(texts []string, embeddings [][]float64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	writer := bufio.NewWriter(c.file)
+	encoder := json.NewEncoder(writer)
+	for i, text := range texts {
+		hash := utils.Hash(text)
+		c.vectors[cacheKey(c.model, hash)] = cloneVector(embeddings[i])
+		if err := encoder.Encode(cacheEntry{Model: c.model, Hash: hash, Embedding: embeddings[i]}); err != nil {
+			return fmt.Errorf("write cache: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("write cache: %w", err)
+	}
+
+	return nil
+}
+
+// cloneVector 复制向量，避免调用方与缓存共享底层数组
+func cloneVector(vec []float64) []float64 {
+	return append([]float64(nil), vec...)
+}
+
+// EmbedSingle 获取单个文本向量
+func (c *CachedClient) EmbedSingle(ctx context.Context, text string) ([]float64, error) {
+	embeddings, err := c.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+// Stats 返回缓存统计
+func (c *CachedClient) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = len(c.vectors)
+	return stats
+}
+
+// Close 关闭缓存文件
+func (c *CachedClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.file.Close()
+}
@@ -0,0 +1,235 @@
+package embedding
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// countingClient 记录底层 Embed 的调用次数和请求的文本
+type countingClient struct {
+	*LocalClient
+	mu    sync.Mutex
+	calls int
+	texts []string
+}
+
+func (c *countingClient) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	c.mu.Lock()
+	c.calls++
+	c.texts = append(c.texts, texts...)
+	c.mu.Unlock()
+	return c.LocalClient.Embed(ctx, texts)
+}
+
+func newCountingClient() *countingClient {
+	return &countingClient{LocalClient: NewLocalClient(16)}
+}
+
+func sameVector(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// TestCachedClientPersistence 向量写入文件，重新打开后不再调用底层客户端
+func TestCachedClientPersistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cache", "embeddings.jsonl")
+
+	inner := newCountingClient()
+	cached, err := NewCachedClient(inner, "local", path)
+	if err != nil {
+		t.Fatalf("NewCachedClient: %v", err)
+	}
+	first, err := cached.Embed(ctx, []string{"爱因斯坦", "居里夫人", "爱因斯坦"})
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if inner.calls != 1 || len(inner.texts) != 2 {
+		t.Errorf("inner got %d calls with %q, want one call with 2 distinct texts", inner.calls, inner.texts)
+	}
+	if err := cached.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	inner = newCountingClient()
+	reopened, err := NewCachedClient(inner, "local", path)
+	if err != nil {
+		t.Fatalf("NewCachedClient: %v", err)
+	}
+	defer reopened.Close()
+
+	second, err := reopened.Embed(ctx, []string{"居里夫人", "爱因斯坦"})
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if inner.calls != 0 {
+		t.Errorf("inner called %d times after reload, want 0", inner.calls)
+	}
+	if !sameVector(second[0], first[1]) || !sameVector(second[1], first[0]) {
+		t.Error("reloaded vectors differ from stored ones")
+	}
+}
+
+// TestCachedClientTruncatedLine 跳过进程中断留下的半行，之后追加的记录仍然可读
+func TestCachedClientTruncatedLine(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "embeddings.jsonl")
+
+	cached, err := NewCachedClient(newCountingClient(), "local", path)
+	if err != nil {
+		t.Fatalf("NewCachedClient: %v", err)
+	}
+	if _, err := cached.Embed(ctx, []string{"爱因斯坦"}); err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	cached.Close()
+
+	// 模拟写到一半时进程退出
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteString(`{"model":"local","hash":"abc","embedding":[0.1,`); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	inner := newCountingClient()
+	cached, err = NewCachedClient(inner, "local", path)
+	if err != nil {
+		t.Fatalf("NewCachedClient: %v", err)
+	}
+	if stats := cached.Stats(); stats.Entries != 1 {
+		t.Errorf("entries = %d, want 1", stats.Entries)
+	}
+	if _, err := cached.Embed(ctx, []string{"爱因斯坦", "居里夫人"}); err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if len(inner.texts) != 1 || inner.texts[0] != "居里夫人" {
+		t.Errorf("inner texts = %q, want only the new text", inner.texts)
+	}
+	cached.Close()
+
+	// 新记录从新的一行开始，不会和半行拼在一起
+	inner = newCountingClient()
+	cached, err = NewCachedClient(inner, "local", path)
+	if err != nil {
+		t.Fatalf("NewCachedClient: %v", err)
+	}
+	defer cached.Close()
+	if _, err := cached.Embed(ctx, []string{"爱因斯坦", "居里夫人"}); err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if inner.calls != 0 {
+		t.Errorf("inner called %d times, want 0", inner.calls)
+	}
+}
+
+// TestCachedClientModelIsolation 同一文件中不同模型的向量互不命中
+func TestCachedClientModelIsolation(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "embeddings.jsonl")
+
+	small, err := NewCachedClient(NewLocalClient(8), "small", path)
+	if err != nil {
+		t.Fatalf("NewCachedClient: %v", err)
+	}
+	if _, err := small.Embed(ctx, []string{"爱因斯坦"}); err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	small.Close()
+
+	inner := &countingClient{LocalClient: NewLocalClient(32)}
+	large, err := NewCachedClient(inner, "large", path)
+	if err != nil {
+		t.Fatalf("NewCachedClient: %v", err)
+	}
+	defer large.Close()
+
+	vec, err := large.EmbedSingle(ctx, "爱因斯坦")
+	if err != nil {
+		t.Fatalf("EmbedSingle: %v", err)
+	}
+	if inner.calls != 1 || len(vec) != 32 {
+		t.Errorf("calls = %d, dimension = %d, want a fresh 32-dim vector", inner.calls, len(vec))
+	}
+
+	// 不指定模型名时从 Model() 获取；都没有时报错
+	if _, err := NewCachedClient(struct{ Client }{NewLocalClient(8)}, "", path); err == nil {
+		t.Error("expected error without model name")
+	}
+	named, err := NewCachedClient(NewLocalClient(8), "", path)
+	if err != nil {
+		t.Fatalf("NewCachedClient: %v", err)
+	}
+	named.Close()
+}
+
+// TestCachedClientStats 命中和未命中按文本计数
+func TestCachedClientStats(t *testing.T) {
+	ctx := context.Background()
+	cached, err := NewCachedClient(newCountingClient(), "local", filepath.Join(t.TempDir(), "embeddings.jsonl"))
+	if err != nil {
+		t.Fatalf("NewCachedClient: %v", err)
+	}
+	defer cached.Close()
+
+	if _, err := cached.Embed(ctx, []string{"a", "b", "a"}); err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if _, err := cached.Embed(ctx, []string{"a", "c"}); err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+
+	want := CacheStats{Hits: 1, Misses: 4, Entries: 3}
+	if stats := cached.Stats(); stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+}
+
+// TestCachedClientReturnsCopies 修改返回的向量不影响缓存，重复文本拿到独立的副本
+func TestCachedClientReturnsCopies(t *testing.T) {
+	ctx := context.Background()
+	cached, err := NewCachedClient(newCountingClient(), "local", filepath.Join(t.TempDir(), "embeddings.jsonl"))
+	if err != nil {
+		t.Fatalf("NewCachedClient: %v", err)
+	}
+	defer cached.Close()
+
+	fresh, err := cached.Embed(ctx, []string{"爱因斯坦", "爱因斯坦"})
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	want := append([]float64(nil), fresh[0]...)
+	fresh[0][0] = 42
+	if fresh[1][0] == 42 {
+		t.Error("duplicate texts share one vector")
+	}
+
+	hit, err := cached.EmbedSingle(ctx, "爱因斯坦")
+	if err != nil {
+		t.Fatalf("EmbedSingle: %v", err)
+	}
+	if !sameVector(hit, want) {
+		t.Error("modifying a fresh result changed the cache")
+	}
+
+	hit[0] = 42
+	again, err := cached.EmbedSingle(ctx, "爱因斯坦")
+	if err != nil {
+		t.Fatalf("EmbedSingle: %v", err)
+	}
+	if !sameVector(again, want) {
+		t.Error("modifying a cache hit changed the cache")
+	}
+}
@@ -3,7 +3,7 @@ package embedding
 // interface.go - 向量存储接口定义
 // 用途：定义统一的向量存储接口，支持不同的实现（内存、Weaviate 等）
 // 主要功能：
-// - VectorStore 接口：插入、搜索、获取等操作
+// - VectorStore 接口：插入、搜索、获取、删除等操作
 
 import "context"
 
@@ -25,4 +25,7 @@ type VectorStore interface {
 
 	// GetContent 根据 ID 获取原始内容
 	GetContent(ctx context.Context, id string) (string, error)
+
+	// Delete 根据 ID 删除向量和内容
+	Delete(ctx context.Context, id string) error
 }
@@ -0,0 +1,130 @@
+package embedding
+
+// local.go - 本地确定性 Embedding 实现
+// 用途：不依赖网络和 API Key 的 embedding 客户端，用于单元测试、CI 和离线演示
+// 主要功能：
+// - LocalClient: 实现 Client 接口
+// - 特征哈希：字符 n-gram（适合中文）+ 单词（适合英文）映射到固定维度，带符号哈希减少冲突偏差
+// - 向量做 L2 归一化，相同文本总是得到相同向量
+// 注意：只反映字面相似度，不理解语义，检索效果不能代表真实 embedding 模型
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// LocalClient 本地特征哈希 Embedding 客户端
+type LocalClient struct {
+	dimension int // 向量维度
+	minN      int // 字符 n-gram 最小长度
+	maxN      int // 字符 n-gram 最大长度
+}
+
+// NewLocalClient 创建本地 embedding 客户端
+// dimension: 向量维度，<= 0 时使用默认值 256
+func NewLocalClient(dimension int) *LocalClient {
+	if dimension <= 0 {
+		dimension = 256
+	}
+	return &LocalClient{
+		dimension: dimension,
+		minN:      1,
+		maxN:      3,
+	}
+}
+
+// SetNGramRange 设置字符 n-gram 的长度范围（默认 1 ~ 3）
+func (c *LocalClient) SetNGramRange(minN, maxN int) {
+	if minN < 1 {
+		minN = 1
+	}
+	if maxN < minN {
+		maxN = minN
+	}
+	c.minN = minN
+	c.maxN = maxN
+}
+
+// Model 返回模型名（包含维度和 n-gram 范围，参数不同的向量不会共用缓存）
+func (c *LocalClient) Model() string {
+	return fmt.Sprintf("local-hash-%d-%d-%d", c.dimension, c.minN, c.maxN)
+}
+
+// Embed 批量获取文本向量
+func (c *LocalClient) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	result := make([][]float64, len(texts))
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result[i] = c.vector(text)
+	}
+	return result, nil
+}
+
+// EmbedSingle 获取单个文本向量
+func (c *LocalClient) EmbedSingle(ctx context.Context, text string) ([]float64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.vector(text), nil
+}
+
+// vector 计算文本的特征哈希向量
+func (c *LocalClient) vector(text string) []float64 {
+	vec := make([]float64, c.dimension)
+	text = strings.ToLower(text)
+
+	// 字符 n-gram（忽略空白和标点，中文按字切分）
+	var runes []rune
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			runes = append(runes, r)
+		}
+	}
+	for n := c.minN; n <= c.maxN; n++ {
+		for i := 0; i+n <= len(runes); i++ {
+			c.add(vec, "c:"+string(runes[i:i+n]))
+		}
+	}
+
+	// 单词
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		c.add(vec, "w:"+word)
+	}
+
+	// L2 归一化
+	var norm float64
+	for _, v := range vec {
+		norm += v * v
+	}
+	if norm > 0 {
+		norm = math.Sqrt(norm)
+		for i := range vec {
+			vec[i] /= norm
+		}
+	}
+
+	return vec
+}
+
+// add 把特征哈希到向量的某一维，哈希的最高位决定符号
+func (c *LocalClient) add(vec []float64, feature string) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	sum := h.Sum64()
+
+	index := int((sum & math.MaxInt64) % uint64(c.dimension))
+	if sum>>63 == 1 {
+		vec[index]--
+	} else {
+		vec[index]++
+	}
+}
@@ -0,0 +1,132 @@
+package embedding
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/example/go-scaffold/pkg/utils"
+)
+
+// TestLocalClientDeterministic 相同参数的客户端对相同文本总是得到相同向量
+func TestLocalClientDeterministic(t *testing.T) {
+	ctx := context.Background()
+	texts := []string{"爱因斯坦提出了相对论", "Marie Curie discovered radium"}
+
+	first, err := NewLocalClient(128).Embed(ctx, texts)
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	second, err := NewLocalClient(128).Embed(ctx, texts)
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	for i := range texts {
+		single, err := NewLocalClient(128).EmbedSingle(ctx, texts[i])
+		if err != nil {
+			t.Fatalf("EmbedSingle: %v", err)
+		}
+		for j := range first[i] {
+			if first[i][j] != second[i][j] || first[i][j] != single[j] {
+				t.Fatalf("text %q: vectors differ at %d", texts[i], j)
+			}
+		}
+	}
+}
+
+// TestLocalClientDimension 向量维度与参数一致，<= 0 时使用默认值 256
+func TestLocalClientDimension(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		dimension int
+		want      int
+	}{
+		{0, 256},
+		{-1, 256},
+		{64, 64},
+		{1536, 1536},
+	}
+
+	for _, tt := range tests {
+		vec, err := NewLocalClient(tt.dimension).EmbedSingle(ctx, "相对论")
+		if err != nil {
+			t.Fatalf("EmbedSingle: %v", err)
+		}
+		if len(vec) != tt.want {
+			t.Errorf("NewLocalClient(%d): dimension %d, want %d", tt.dimension, len(vec), tt.want)
+		}
+	}
+}
+
+// TestLocalClientNormalized 向量做 L2 归一化；没有字母和数字的文本得到零向量
+func TestLocalClientNormalized(t *testing.T) {
+	ctx := context.Background()
+	client := NewLocalClient(0)
+
+	for _, text := range []string{"爱因斯坦", "Albert Einstein was born in Ulm", "E = mc²，1905 年"} {
+		vec, err := client.EmbedSingle(ctx, text)
+		if err != nil {
+			t.Fatalf("EmbedSingle: %v", err)
+		}
+		var norm float64
+		for _, v := range vec {
+			norm += v * v
+		}
+		if math.Abs(math.Sqrt(norm)-1) > 1e-9 {
+			t.Errorf("%q: norm %v, want 1", text, math.Sqrt(norm))
+		}
+	}
+
+	vec, err := client.EmbedSingle(ctx, "，。 !?")
+	if err != nil {
+		t.Fatalf("EmbedSingle: %v", err)
+	}
+	for _, v := range vec {
+		if v != 0 {
+			t.Fatalf("punctuation only: got non-zero vector")
+		}
+	}
+}
+
+// TestLocalClientSimilarity 字面相近的文本比无关文本更相似，大小写不影响结果
+func TestLocalClientSimilarity(t *testing.T) {
+	ctx := context.Background()
+	client := NewLocalClient(0)
+
+	vecs, err := client.Embed(ctx, []string{"阿尔伯特·爱因斯坦", "爱因斯坦", "居里夫人", "Einstein", "EINSTEIN"})
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	related := utils.CosineSimilarity(vecs[0], vecs[1])
+	unrelated := utils.CosineSimilarity(vecs[0], vecs[2])
+	if related <= unrelated {
+		t.Errorf("similarity: related %v <= unrelated %v", related, unrelated)
+	}
+	if sim := utils.CosineSimilarity(vecs[3], vecs[4]); math.Abs(sim-1) > 1e-9 {
+		t.Errorf("case-insensitive similarity: got %v, want 1", sim)
+	}
+}
+
+// TestLocalClientModel 参数不同的客户端模型名不同，向量缓存不会混用
+func TestLocalClientModel(t *testing.T) {
+	a := NewLocalClient(128)
+	b := NewLocalClient(128)
+	b.SetNGramRange(2, 4)
+
+	if a.Model() == b.Model() || a.Model() == NewLocalClient(256).Model() {
+		t.Errorf("model names collide: %s, %s", a.Model(), b.Model())
+	}
+}
+
+// TestLocalClientCanceled context 取消时返回错误
+func TestLocalClientCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := NewLocalClient(0).Embed(ctx, []string{"a"}); err == nil {
+		t.Error("Embed with canceled context: want error")
+	}
+	if _, err := NewLocalClient(0).EmbedSingle(ctx, "a"); err == nil {
+		t.Error("EmbedSingle with canceled context: want error")
+	}
+}
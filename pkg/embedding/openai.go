@@ -4,7 +4,8 @@ package embedding
 // 用途：使用 OpenAI API 将文本转换为向量
 // 主要功能：
 // - OpenAIClient: 实现 Client 接口，调用 OpenAI embedding API
-// - 支持批量处理文本向量化
+// - 支持批量处理文本向量化（超出单次请求上限时自动拆分批次并发请求）
+// - 429 / 5xx / 网络错误自动重试（指数退避 + 抖动，遵守 Retry-After），可选客户端限流
 
 import (
 	"bytes"
@@ -14,14 +15,23 @@ import (
 	"io"
 	"net/http"
 	"os"
+
+	"github.com/example/go-scaffold/pkg/retry"
 )
 
 // OpenAIClient OpenAI Embedding 客户端
 type OpenAIClient struct {
-	apiKey  string
-	model   string
-	baseURL string
-	client  *http.Client
+	apiKey      string
+	model       string
+	baseURL     string
+	client      *http.Client
+	retryPolicy retry.Policy
+	limiter     *retry.Limiter // 可与其他客户端共享，nil 表示不限流
+
+	// 批量请求参数（见 batch.go）
+	maxBatchSize   int // 每个请求的最大文本数
+	maxBatchTokens int // 每个请求的最大估算 token 数
+	concurrency    int // 同时进行的请求数
 }
 
 // NewOpenAIClient 创建 OpenAI 客户端
@@ -37,13 +47,33 @@ func NewOpenAIClient(apiKey string, model string) *OpenAIClient {
 	}
 
 	return &OpenAIClient{
-		apiKey:  apiKey,
-		model:   model,
-		baseURL: baseURL,
-		client:  &http.Client{},
+		apiKey:      apiKey,
+		model:       model,
+		baseURL:     baseURL,
+		client:      &http.Client{},
+		retryPolicy: retry.DefaultPolicy(),
+
+		maxBatchSize:   defaultMaxBatchSize,
+		maxBatchTokens: defaultMaxBatchTokens,
+		concurrency:    defaultBatchConcurrency,
 	}
 }
 
+// Model 返回模型名
+func (c *OpenAIClient) Model() string {
+	return c.model
+}
+
+// SetRetryPolicy 设置重试策略（默认 retry.DefaultPolicy，retry.NoRetry 关闭重试）
+func (c *OpenAIClient) SetRetryPolicy(policy retry.Policy) {
+	c.retryPolicy = policy
+}
+
+// SetRateLimiter 设置客户端限流器，同一个限流器可以在多个客户端之间共享
+func (c *OpenAIClient) SetRateLimiter(limiter *retry.Limiter) {
+	c.limiter = limiter
+}
+
 type embeddingRequest struct {
 	Input []string `json:"input"`
 	Model string   `json:"model"`
@@ -61,21 +91,62 @@ type embeddingResponse struct {
 }
 
 // Embed 批量获取文本向量
+// 输入超过单次请求的条数或 token 上限时自动拆分成多个批次并发请求（见 batch.go），结果按输入顺序返回
 func (c *OpenAIClient) Embed(ctx context.Context, texts []string) ([][]float64, error) {
 	if len(texts) == 0 {
 		return [][]float64{}, nil
 	}
 
+	result := make([][]float64, len(texts))
+	batches := splitBatches(texts, c.maxBatchSize, c.maxBatchTokens)
+	if err := c.embedBatches(ctx, batches, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// embedBatch 请求一个批次的向量，写入 result[b.offset:]
+func (c *OpenAIClient) embedBatch(ctx context.Context, b batch, result [][]float64) error {
 	reqBody := embeddingRequest{
-		Input: texts,
+		Input: b.texts,
 		Model: c.model,
 	}
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("marshal request: %w", err)
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	var embResp *embeddingResponse
+	err = retry.Do(ctx, c.retryPolicy, func(ctx context.Context) error {
+		if err := c.limiter.Wait(ctx, b.tokens); err != nil {
+			return err
+		}
+		embResp, err = c.send(ctx, jsonData)
+		return err
+	})
+	if err != nil {
+		return err
 	}
 
+	// 按 index 排序结果（index 是批次内的下标）
+	for _, data := range embResp.Data {
+		if data.Index >= 0 && data.Index < len(b.texts) {
+			result[b.offset+data.Index] = data.Embedding
+		}
+	}
+	for i := range b.texts {
+		if result[b.offset+i] == nil {
+			return fmt.Errorf("no embedding returned for input %d", b.offset+i)
+		}
+	}
+
+	return nil
+}
+
+// send 发送一次请求
+func (c *OpenAIClient) send(ctx context.Context, jsonData []byte) (*embeddingResponse, error) {
 	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/embeddings", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
@@ -95,6 +166,10 @@ func (c *OpenAIClient) Embed(ctx context.Context, texts []string) ([][]float64,
 		return nil, fmt.Errorf("read response: %w", err)
 	}
 
+	if err := retry.CheckResponse(resp, body); err != nil {
+		return nil, err
+	}
+
 	var embResp embeddingResponse
 	if err := json.Unmarshal(body, &embResp); err != nil {
 		return nil, fmt.Errorf("unmarshal response: %w", err)
@@ -104,15 +179,7 @@ func (c *OpenAIClient) Embed(ctx context.Context, texts []string) ([][]float64,
 		return nil, fmt.Errorf("openai error: %s", embResp.Error.Message)
 	}
 
-	// 按 index 排序结果
-	result := make([][]float64, len(texts))
-	for _, data := range embResp.Data {
-		if data.Index < len(result) {
-			result[data.Index] = data.Embedding
-		}
-	}
-
-	return result, nil
+	return &embResp, nil
 }
 
 // EmbedSingle 获取单个文本向量
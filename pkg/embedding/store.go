@@ -6,6 +6,7 @@ package embedding
 // - Insert: 插入文本并自动生成向量（自动去重）
 // - Search: 基于向量相似度搜索最相关的文本
 // - Get/GetContent: 根据 ID 获取向量或原始内容
+// - Delete: 根据 ID 删除
 // - Save/Load: 持久化存储到文件
 
 import (
@@ -39,55 +40,63 @@ func NewStore(client Client) *Store {
 }
 
 // Insert 插入文本并生成向量
+// 向量在锁外生成，只有写入时才持有写锁，因此插入期间 Search / Get / GetContent 不会被 embedding 请求阻塞
 func (s *Store) Insert(ctx context.Context, texts []string) ([]string, error) {
 	if len(texts) == 0 {
 		return []string{}, nil
 	}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	// 去重：检查哪些文本已存在
+	// 去重：检查哪些文本已存在（同一批次中重复的文本只生成一次向量）
+	hashes := make([]string, len(texts))
 	var newTexts []string
-	var newIndices []int
-	textToID := make(map[int]string)
+	pending := make(map[string]bool)
 
+	s.mu.RLock()
 	for i, text := range texts {
 		hash := utils.Hash(text)
-		if existingID, exists := s.hashToID[hash]; exists {
-			textToID[i] = existingID
-		} else {
-			newTexts = append(newTexts, text)
-			newIndices = append(newIndices, i)
+		hashes[i] = hash
+		if _, exists := s.hashToID[hash]; exists || pending[hash] {
+			continue
 		}
+		pending[hash] = true
+		newTexts = append(newTexts, text)
 	}
+	s.mu.RUnlock()
 
-	// 为新文本生成向量
+	// 为新文本生成向量（不持有锁）
 	var embeddings [][]float64
-	var err error
 	if len(newTexts) > 0 {
+		var err error
 		embeddings, err = s.client.Embed(ctx, newTexts)
 		if err != nil {
 			return nil, fmt.Errorf("embed texts: %w", err)
 		}
+		if len(embeddings) != len(newTexts) {
+			return nil, fmt.Errorf("embed texts: got %d embeddings for %d texts", len(embeddings), len(newTexts))
+		}
 	}
 
 	// 存储新文本和向量
-	ids := make([]string, len(texts))
-	for i, idx := range newIndices {
-		text := texts[idx]
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, text := range newTexts {
 		hash := utils.Hash(text)
+		if _, exists := s.hashToID[hash]; exists {
+			// 生成向量期间已被并发插入
+			continue
+		}
 		id := hash[:16] // 使用哈希前16位作为ID
 
 		s.embeddings[id] = embeddings[i]
 		s.contents[id] = text
 		s.hashToID[hash] = id
-		textToID[idx] = id
 	}
 
 	// 构建返回的ID列表
-	for i := range texts {
-		ids[i] = textToID[i]
+	ids := make([]string, len(texts))
+	for i, hash := range hashes {
+		ids[i] = s.hashToID[hash]
 	}
 
 	return ids, nil
@@ -117,6 +126,22 @@ func (s *Store) GetContent(ctx context.Context, id string) (string, error) {
 	return content, nil
 }
 
+// Delete 删除向量和内容
+func (s *Store) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	content, exists := s.contents[id]
+	if !exists {
+		return fmt.Errorf("content not found: %s", id)
+	}
+
+	delete(s.embeddings, id)
+	delete(s.contents, id)
+	delete(s.hashToID, utils.Hash(content))
+	return nil
+}
+
 type searchResult struct {
 	id    string
 	score float64
@@ -206,6 +231,17 @@ func (s *Store) Load(path string) error {
 	s.contents = data.Contents
 	s.hashToID = data.HashToID
 
+	// 空存储保存后字段可能为 null
+	if s.embeddings == nil {
+		s.embeddings = make(map[string][]float64)
+	}
+	if s.contents == nil {
+		s.contents = make(map[string]string)
+	}
+	if s.hashToID == nil {
+		s.hashToID = make(map[string]string)
+	}
+
 	return nil
 }
 
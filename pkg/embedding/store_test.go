@@ -0,0 +1,90 @@
+package embedding
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// blockingClient 在 release 关闭前阻塞 Embed，模拟耗时的 embedding 请求（含重试退避）
+type blockingClient struct {
+	*LocalClient
+	started chan struct{}
+	release chan struct{}
+}
+
+func (c *blockingClient) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	close(c.started)
+	<-c.release
+	return c.LocalClient.Embed(ctx, texts)
+}
+
+// TestStoreInsertDoesNotBlockReads Insert 等待 embedding 期间 Search / GetContent 可以正常执行
+func TestStoreInsertDoesNotBlockReads(t *testing.T) {
+	ctx := context.Background()
+	local := NewLocalClient(64)
+	store := NewStore(local)
+
+	ids, err := store.Insert(ctx, []string{"爱因斯坦提出了相对论"})
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	blocking := &blockingClient{LocalClient: local, started: make(chan struct{}), release: make(chan struct{})}
+	store.client = blocking
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := store.Insert(ctx, []string{"居里夫人发现了镭", "爱因斯坦提出了相对论"})
+		done <- err
+	}()
+	<-blocking.started
+
+	reads := make(chan struct{})
+	go func() {
+		defer close(reads)
+		query, _ := local.EmbedSingle(ctx, "相对论")
+		if found, _, err := store.Search(ctx, query, 1); err != nil || len(found) != 1 {
+			t.Errorf("Search during insert: %v, %v", found, err)
+		}
+		if _, err := store.GetContent(ctx, ids[0]); err != nil {
+			t.Errorf("GetContent during insert: %v", err)
+		}
+	}()
+
+	select {
+	case <-reads:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reads blocked while Insert was waiting for embeddings")
+	}
+
+	close(blocking.release)
+	if err := <-done; err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if store.Size() != 2 {
+		t.Errorf("Size = %d, want 2", store.Size())
+	}
+}
+
+// TestStoreInsertDeduplicates 已存在和同一批次中重复的文本复用同一个 ID
+func TestStoreInsertDeduplicates(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(NewLocalClient(64))
+
+	first, err := store.Insert(ctx, []string{"a", "b", "a"})
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	second, err := store.Insert(ctx, []string{"b", "c"})
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	if first[0] != first[2] || first[1] != second[0] || second[1] == "" {
+		t.Errorf("unexpected ids: %v, %v", first, second)
+	}
+	if store.Size() != 3 {
+		t.Errorf("Size = %d, want 3", store.Size())
+	}
+}
@@ -0,0 +1,272 @@
+package graph
+
+// csr.go - 稀疏矩阵（CSR）快照
+// 用途：把 Graph 编译为只读的压缩稀疏行（CSR）结构，在大图上高效执行 PPR
+// 主要功能：
+// - CSR: 获取图的 CSR 快照（图变更后惰性重建）
+// - CSR.PPR: 按边权重传播的 PPR（与 Graph.WeightedPPR 语义一致）
+// - CSR.UniformPPR: 平均分配的 PPR（与 Graph.PPR 语义一致）
+// 节点使用稠密下标，分数使用 float64 向量，迭代过程中不分配 map
+
+import (
+	"math"
+	"sort"
+)
+
+// CSR 图的只读稀疏矩阵快照
+// 第 i 个节点的出边为 colIdx[rowPtr[i]:rowPtr[i+1]]
+type CSR struct {
+	ids       []string       // 下标 -> 节点 ID（按 ID 排序）
+	nodeTypes []string       // 下标 -> 节点类型
+	index     map[string]int // 节点 ID -> 下标
+
+	rowPtr    []int     // 行偏移，长度为节点数 + 1
+	colIdx    []int     // 出边目标节点下标
+	weights   []float64 // 边权重
+	edgeTypes []int     // 边类型下标（对应 typeNames）
+	typeNames []string  // 边类型名称
+}
+
+// CSR 返回图的 CSR 快照
+// 图未发生变化时复用上次编译的结果；快照只读，可被多个 goroutine 共享
+func (g *Graph) CSR() *CSR {
+	g.csrMu.Lock()
+	defer g.csrMu.Unlock()
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if g.csr == nil || g.csrVersion != g.version {
+		g.csr = g.compile()
+		g.csrVersion = g.version
+	}
+	return g.csr
+}
+
+// compile 编译 CSR 快照，调用方需持有读锁
+func (g *Graph) compile() *CSR {
+	ids := make([]string, 0, len(g.nodes))
+	for id := range g.nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	c := &CSR{
+		ids:       ids,
+		nodeTypes: make([]string, len(ids)),
+		index:     make(map[string]int, len(ids)),
+		rowPtr:    make([]int, len(ids)+1),
+	}
+	for i, id := range ids {
+		c.index[id] = i
+		c.nodeTypes[i] = g.nodes[id].Type
+	}
+
+	typeIndex := make(map[string]int)
+	for i, id := range ids {
+		edges := g.edges[id]
+		targets := make([]string, 0, len(edges))
+		for to := range edges {
+			if _, exists := c.index[to]; exists {
+				targets = append(targets, to)
+			}
+		}
+		sort.Strings(targets)
+
+		for _, to := range targets {
+			edge := edges[to]
+			t, exists := typeIndex[edge.Type]
+			if !exists {
+				t = len(c.typeNames)
+				typeIndex[edge.Type] = t
+				c.typeNames = append(c.typeNames, edge.Type)
+			}
+
+			c.colIdx = append(c.colIdx, c.index[to])
+			c.weights = append(c.weights, edge.Weight)
+			c.edgeTypes = append(c.edgeTypes, t)
+		}
+		c.rowPtr[i+1] = len(c.colIdx)
+	}
+
+	return c
+}
+
+// NodeCount 返回节点数量
+func (c *CSR) NodeCount() int {
+	return len(c.ids)
+}
+
+// EdgeCount 返回边数量
+func (c *CSR) EdgeCount() int {
+	return len(c.colIdx)
+}
+
+// Index 返回节点 ID 对应的稠密下标
+func (c *CSR) Index(id string) (int, bool) {
+	i, exists := c.index[id]
+	return i, exists
+}
+
+// ID 返回下标对应的节点 ID
+func (c *CSR) ID(i int) string {
+	return c.ids[i]
+}
+
+// NodeType 返回下标对应的节点类型
+func (c *CSR) NodeType(i int) string {
+	return c.nodeTypes[i]
+}
+
+// PPR 按边权重传播的 Personalized PageRank
+// 参数含义与 Graph.WeightedPPR 相同，返回分数非零的节点
+func (c *CSR) PPR(
+	seedWeights map[string]float64,
+	damping float64,
+	maxIter int,
+	tolerance float64,
+	typeWeights map[string]float64,
+) map[string]float64 {
+	return c.toMap(c.PPRVector(c.SeedVector(seedWeights), damping, maxIter, tolerance, c.Transitions(typeWeights, true)))
+}
+
+// UniformPPR 平均分配的 Personalized PageRank
+// 参数含义与 Graph.PPR 相同，返回分数非零的节点
+func (c *CSR) UniformPPR(
+	seedWeights map[string]float64,
+	damping float64,
+	maxIter int,
+	tolerance float64,
+) map[string]float64 {
+	return c.toMap(c.PPRVector(c.SeedVector(seedWeights), damping, maxIter, tolerance, c.Transitions(nil, false)))
+}
+
+// SeedVector 把种子权重转换为归一化的稠密向量
+// 不在图中的种子节点会被忽略；没有有效种子时返回 nil
+func (c *CSR) SeedVector(seedWeights map[string]float64) []float64 {
+	seed := make([]float64, len(c.ids))
+	total := 0.0
+	for id, weight := range seedWeights {
+		if i, exists := c.index[id]; exists && weight > 0 {
+			seed[i] += weight
+			total += weight
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+
+	for i := range seed {
+		seed[i] /= total
+	}
+	return seed
+}
+
+// Transitions 计算每条边的转移概率（每行归一化）
+// weighted 为 false 时每条出边概率相同；为 true 时按 EffectiveWeight 归一化
+// 没有有效出边的行概率全为 0（视为悬挂节点）
+func (c *CSR) Transitions(typeWeights map[string]float64, weighted bool) []float64 {
+	multipliers := make([]float64, len(c.typeNames))
+	for t, name := range c.typeNames {
+		multipliers[t] = 1.0
+		if m, exists := typeWeights[name]; exists {
+			multipliers[t] = m
+		}
+	}
+
+	probs := make([]float64, len(c.colIdx))
+	for u := 0; u < len(c.ids); u++ {
+		start, end := c.rowPtr[u], c.rowPtr[u+1]
+
+		total := 0.0
+		for k := start; k < end; k++ {
+			w := 1.0
+			if weighted {
+				w = math.Max(c.weights[k]*multipliers[c.edgeTypes[k]], 0)
+			}
+			probs[k] = w
+			total += w
+		}
+		if total <= 0 {
+			continue
+		}
+		for k := start; k < end; k++ {
+			probs[k] /= total
+		}
+	}
+
+	return probs
+}
+
+// PPRVector 在稠密向量上执行 PPR 幂迭代
+// seed: 归一化的种子向量（SeedVector 的结果）
+// probs: 每条边的转移概率（Transitions 的结果）
+// 悬挂节点的分数按种子分布回流，与 Graph.PPR 一致
+func (c *CSR) PPRVector(seed []float64, damping float64, maxIter int, tolerance float64, probs []float64) []float64 {
+	n := len(c.ids)
+	if seed == nil {
+		return make([]float64, n)
+	}
+
+	dangling := make([]bool, n)
+	for u := 0; u < n; u++ {
+		total := 0.0
+		for k := c.rowPtr[u]; k < c.rowPtr[u+1]; k++ {
+			total += probs[k]
+		}
+		dangling[u] = total == 0
+	}
+
+	scores := make([]float64, n)
+	copy(scores, seed)
+	next := make([]float64, n)
+
+	for iter := 0; iter < maxIter; iter++ {
+		for i := range next {
+			next[i] = 0
+		}
+
+		// 传播分数，悬挂节点的分数累加后按种子分布回流
+		danglingMass := 0.0
+		for u, score := range scores {
+			if score == 0 {
+				continue
+			}
+			if dangling[u] {
+				danglingMass += score
+				continue
+			}
+			for k := c.rowPtr[u]; k < c.rowPtr[u+1]; k++ {
+				next[c.colIdx[k]] += score * probs[k]
+			}
+		}
+
+		// 应用阻尼并检查收敛
+		maxDiff := 0.0
+		for i := range next {
+			next[i] = (1-damping)*seed[i] + damping*(next[i]+danglingMass*seed[i])
+			if diff := math.Abs(next[i] - scores[i]); diff > maxDiff {
+				maxDiff = diff
+			}
+		}
+
+		scores, next = next, scores
+
+		if maxDiff <= tolerance {
+			break
+		}
+	}
+
+	return scores
+}
+
+// toMap 把稠密分数向量转换为 节点 ID -> 分数（仅保留非零项）
+func (c *CSR) toMap(scores []float64) map[string]float64 {
+	result := make(map[string]float64)
+	for i, score := range scores {
+		if score != 0 {
+			result[c.ids[i]] = score
+		}
+	}
+	return result
+}
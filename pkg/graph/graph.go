@@ -4,8 +4,12 @@ package graph
 // 用途：构建和管理实体关系图谱，支持多种类型的节点和边
 // 主要功能：
 // - AddNode: 添加节点（实体或文档块）
-// - AddEdge: 添加边（事实关系、段落关系、同义关系）
+// - AddEdge: 添加边（事实关系、段落关系、同义关系），重复添加只更新权重
+// - Merge: 将另一张图原子地合并进来（用于增量索引）
+// - RemoveNode/RemoveEdge: 删除节点（连同关联边）或单条边
 // - GetNeighbors: 获取节点的邻居
+// - CSR: 获取只读的稀疏矩阵快照（图变更后惰性重建）
+// - Save/Load: 持久化（见 persist.go）
 // - 支持并发安全的图操作
 
 import "sync"
@@ -16,20 +20,25 @@ type Graph struct {
 	nodes   map[string]*Node            // 节点：实体 + 文档块
 	edges   map[string]map[string]*Edge // 边：事实边 + 段落边 + 同义边
 	adjList map[string][]string         // 邻接表（用于 PPR）
+	version uint64                      // 每次修改递增，用于判断 CSR 快照是否过期
 	mu      sync.RWMutex
+
+	csr        *CSR       // 最近一次编译的 CSR 快照
+	csrVersion uint64     // csr 编译时的图版本
+	csrMu      sync.Mutex // 保护 csr，避免并发重复编译
 }
 
 type Node struct {
-	ID      string
-	Content string
-	Type    string // "entity" 或 "chunk"
+	ID      string `json:"id"`
+	Content string `json:"content"`
+	Type    string `json:"type"` // "entity" 或 "chunk"
 }
 
 type Edge struct {
-	From   string
-	To     string
-	Weight float64
-	Type   string // "fact", "passage", "synonymy"
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Weight float64 `json:"weight"`
+	Type   string  `json:"type"` // "fact", "passage", "synonymy"
 }
 
 // NewGraph 创建新的知识图谱
@@ -51,6 +60,7 @@ func (g *Graph) AddNode(id, content, nodeType string) {
 		Content: content,
 		Type:    nodeType,
 	}
+	g.version++
 
 	// 初始化邻接表
 	if _, exists := g.adjList[id]; !exists {
@@ -71,20 +81,111 @@ func (g *Graph) AddEdge(from, to string, weight float64, edgeType string) {
 		return
 	}
 
-	// 添加边
-	if g.edges[from] == nil {
-		g.edges[from] = make(map[string]*Edge)
-	}
-
-	g.edges[from][to] = &Edge{
+	g.setEdge(&Edge{
 		From:   from,
 		To:     to,
 		Weight: weight,
 		Type:   edgeType,
+	})
+	g.version++
+}
+
+// setEdge 添加或覆盖边，调用方需持有写锁
+// 已存在的边只更新权重和类型，不会在邻接表中重复添加
+func (g *Graph) setEdge(edge *Edge) {
+	if g.edges[edge.From] == nil {
+		g.edges[edge.From] = make(map[string]*Edge)
+	}
+
+	if _, exists := g.edges[edge.From][edge.To]; !exists {
+		// 更新邻接表
+		g.adjList[edge.From] = append(g.adjList[edge.From], edge.To)
+	}
+	g.edges[edge.From][edge.To] = edge
+}
+
+// Merge 将 other 中的所有节点和边合并到当前图
+// 整个合并在一次加锁内完成，并发读取者要么看到合并前的图，要么看到合并后的图
+// 同 ID 的节点和同端点的边以 other 为准
+func (g *Graph) Merge(other *Graph) {
+	other.mu.RLock()
+	defer other.mu.RUnlock()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for id, node := range other.nodes {
+		g.nodes[id] = &Node{ID: node.ID, Content: node.Content, Type: node.Type}
+		if _, exists := g.adjList[id]; !exists {
+			g.adjList[id] = []string{}
+		}
+	}
+
+	for _, edges := range other.edges {
+		for _, edge := range edges {
+			copied := *edge
+			g.setEdge(&copied)
+		}
+	}
+
+	g.version++
+}
+
+// RemoveNode 删除节点及其所有出边和入边
+func (g *Graph) RemoveNode(id string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, exists := g.nodes[id]; !exists {
+		return
+	}
+
+	// 删除入边
+	for from, edges := range g.edges {
+		if _, exists := edges[id]; exists {
+			g.removeEdge(from, id)
+		}
+	}
+
+	delete(g.nodes, id)
+	delete(g.edges, id)
+	delete(g.adjList, id)
+	g.version++
+}
+
+// RemoveEdge 删除边 from -> to
+func (g *Graph) RemoveEdge(from, to string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.removeEdge(from, to) {
+		g.version++
+	}
+}
+
+// removeEdge 删除边并更新邻接表，调用方需持有写锁
+// 返回边是否存在
+func (g *Graph) removeEdge(from, to string) bool {
+	if _, exists := g.edges[from][to]; !exists {
+		return false
+	}
+
+	delete(g.edges[from], to)
+	if len(g.edges[from]) == 0 {
+		delete(g.edges, from)
 	}
 
-	// 更新邻接表
-	g.adjList[from] = append(g.adjList[from], to)
+	// GetNeighbors 返回的切片可能仍被调用方持有，这里分配新切片而不是原地修改
+	neighbors := make([]string, 0, len(g.adjList[from]))
+	for _, neighborID := range g.adjList[from] {
+		if neighborID != to {
+			neighbors = append(neighbors, neighborID)
+		}
+	}
+	if _, exists := g.nodes[from]; exists {
+		g.adjList[from] = neighbors
+	}
+	return true
 }
 
 // GetNode 获取节点
@@ -0,0 +1,116 @@
+package graph
+
+// persist.go - 知识图谱持久化
+// 用途：把图保存到磁盘并重新加载，避免每次启动都重新构建
+// 格式：带版本号的 JSON，包含所有节点（ID、内容、类型）和边（端点、权重、类型）
+// 节点按 ID 排序、边按 (From, To) 排序，相同的图总是产生相同的文件
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// 文件格式标识和版本
+const (
+	fileFormat  = "hipporag-graph"
+	fileVersion = 1
+)
+
+type graphFile struct {
+	Format  string  `json:"format"`
+	Version int     `json:"version"`
+	Nodes   []*Node `json:"nodes"`
+	Edges   []*Edge `json:"edges"`
+}
+
+// Save 将图写入 w
+func (g *Graph) Save(w io.Writer) error {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	data := graphFile{
+		Format:  fileFormat,
+		Version: fileVersion,
+		Nodes:   make([]*Node, 0, len(g.nodes)),
+		Edges:   make([]*Edge, 0),
+	}
+
+	for _, node := range g.nodes {
+		data.Nodes = append(data.Nodes, node)
+	}
+	sort.Slice(data.Nodes, func(i, j int) bool {
+		return data.Nodes[i].ID < data.Nodes[j].ID
+	})
+
+	for _, edges := range g.edges {
+		for _, edge := range edges {
+			data.Edges = append(data.Edges, edge)
+		}
+	}
+	sort.Slice(data.Edges, func(i, j int) bool {
+		if data.Edges[i].From != data.Edges[j].From {
+			return data.Edges[i].From < data.Edges[j].From
+		}
+		return data.Edges[i].To < data.Edges[j].To
+	})
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return fmt.Errorf("encode graph: %w", err)
+	}
+
+	return nil
+}
+
+// Load 从 r 读取图，替换当前图的全部内容
+func (g *Graph) Load(r io.Reader) error {
+	var data graphFile
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return fmt.Errorf("decode graph: %w", err)
+	}
+
+	if data.Format != fileFormat {
+		return fmt.Errorf("unknown graph format: %q", data.Format)
+	}
+	if data.Version != fileVersion {
+		return fmt.Errorf("unsupported graph version: %d", data.Version)
+	}
+
+	nodes := make(map[string]*Node, len(data.Nodes))
+	adjList := make(map[string][]string, len(data.Nodes))
+	for i, node := range data.Nodes {
+		if node == nil {
+			return fmt.Errorf("node %d: null entry", i)
+		}
+		nodes[node.ID] = node
+		adjList[node.ID] = []string{}
+	}
+
+	for i, edge := range data.Edges {
+		if edge == nil {
+			return fmt.Errorf("edge %d: null entry", i)
+		}
+		if _, exists := nodes[edge.From]; !exists {
+			return fmt.Errorf("edge %s -> %s: unknown node %s", edge.From, edge.To, edge.From)
+		}
+		if _, exists := nodes[edge.To]; !exists {
+			return fmt.Errorf("edge %s -> %s: unknown node %s", edge.From, edge.To, edge.To)
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.nodes = nodes
+	g.edges = make(map[string]map[string]*Edge)
+	g.adjList = adjList
+	for _, edge := range data.Edges {
+		g.setEdge(edge)
+	}
+	g.version++
+
+	return nil
+}
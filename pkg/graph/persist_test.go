@@ -0,0 +1,69 @@
+package graph
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestSaveLoad 保存后加载得到相同的图，相同的图总是产生相同的文件
+func TestSaveLoad(t *testing.T) {
+	g := buildWeightedGraph()
+
+	var first bytes.Buffer
+	if err := g.Save(&first); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded := NewGraph()
+	loaded.AddNode("stale", "", "entity")
+	if err := loaded.Load(bytes.NewReader(first.Bytes())); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if loaded.NodeCount() != 3 || loaded.EdgeCount() != 2 {
+		t.Errorf("loaded %d nodes, %d edges, want 3, 2", loaded.NodeCount(), loaded.EdgeCount())
+	}
+	if _, exists := loaded.GetNode("stale"); exists {
+		t.Error("Load kept a node from before")
+	}
+	if edge, exists := loaded.GetEdge("A", "B"); !exists || edge.Weight != 3 || edge.Type != "fact" {
+		t.Errorf("edge A -> B = %+v, %v", edge, exists)
+	}
+
+	var second bytes.Buffer
+	if err := loaded.Save(&second); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if first.String() != second.String() {
+		t.Errorf("saved files differ:\n%s\n%s", first.String(), second.String())
+	}
+}
+
+// TestLoadErrors 格式不对、节点或边为 null、边的端点不存在时返回错误且不修改原图
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"invalid json", `{"format": `, "decode graph"},
+		{"format", `{"format": "other", "version": 1}`, "unknown graph format"},
+		{"version", `{"format": "hipporag-graph", "version": 2}`, "unsupported graph version"},
+		{"null node", `{"format": "hipporag-graph", "version": 1, "nodes": [{"id": "A"}, null]}`, "node 1: null entry"},
+		{"null edge", `{"format": "hipporag-graph", "version": 1, "nodes": [{"id": "A"}], "edges": [null]}`, "edge 0: null entry"},
+		{"unknown node", `{"format": "hipporag-graph", "version": 1, "nodes": [{"id": "A"}], "edges": [{"from": "A", "to": "B"}]}`, "unknown node B"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := buildWeightedGraph()
+			err := g.Load(strings.NewReader(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want %q", err, tt.want)
+			}
+			if g.NodeCount() != 3 || g.EdgeCount() != 2 {
+				t.Errorf("graph changed after a failed Load: %d nodes, %d edges", g.NodeCount(), g.EdgeCount())
+			}
+		})
+	}
+}
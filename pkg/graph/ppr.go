@@ -4,6 +4,7 @@ package graph
 // 用途：在知识图谱上执行个性化 PageRank，用于图检索
 // 主要功能：
 // - PPR: 从种子节点出发，计算所有节点的重要性分数
+// - WeightedPPR: 按归一化的出边权重传播分数，支持按边类型设置权重乘数
 // - 支持自定义阻尼系数、迭代次数和收敛阈值
 
 import "math"
@@ -23,6 +24,82 @@ func (g *Graph) PPR(
 	g.mu.RLock()
 	defer g.mu.RUnlock()
 
+	// 平均分配给邻居
+	return g.ppr(seedWeights, damping, maxIter, tolerance, func(nodeID string, score float64, out map[string]float64) bool {
+		neighbors := g.adjList[nodeID]
+		if len(neighbors) == 0 {
+			return false
+		}
+
+		sharePerNeighbor := score / float64(len(neighbors))
+		for _, neighborID := range neighbors {
+			out[neighborID] += sharePerNeighbor
+		}
+		return true
+	})
+}
+
+// WeightedPPR 执行按边权重传播的 Personalized PageRank 算法
+// 每个节点的分数按 (边权重 × 边类型乘数) 归一化后分配给出边邻居，
+// 因此 Index 中设置的 fact / fact_back 等不同权重会影响传播结果
+// typeWeights: 边类型 -> 权重乘数（例如 "passage": 1.0, "synonymy": 0.5），
+// 未出现的类型乘数为 1.0，乘数为 0 的边类型不参与传播
+// 其余参数与 PPR 相同
+func (g *Graph) WeightedPPR(
+	seedWeights map[string]float64,
+	damping float64,
+	maxIter int,
+	tolerance float64,
+	typeWeights map[string]float64,
+) map[string]float64 {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.ppr(seedWeights, damping, maxIter, tolerance, func(nodeID string, score float64, out map[string]float64) bool {
+		edges := g.edges[nodeID]
+
+		totalWeight := 0.0
+		for _, edge := range edges {
+			totalWeight += EffectiveWeight(edge, typeWeights)
+		}
+		if totalWeight <= 0 {
+			return false
+		}
+
+		for neighborID, edge := range edges {
+			if w := EffectiveWeight(edge, typeWeights); w > 0 {
+				out[neighborID] += score * w / totalWeight
+			}
+		}
+		return true
+	})
+}
+
+// EffectiveWeight 返回边在传播时使用的权重：边权重 × 边类型乘数
+// 负权重按 0 处理
+func EffectiveWeight(edge *Edge, typeWeights map[string]float64) float64 {
+	weight := edge.Weight
+	if multiplier, exists := typeWeights[edge.Type]; exists {
+		weight *= multiplier
+	}
+	if weight < 0 {
+		return 0
+	}
+	return weight
+}
+
+// spreadFunc 将节点分数分配给邻居，写入 out
+// 返回 false 表示该节点没有可传播的出边
+type spreadFunc func(nodeID string, score float64, out map[string]float64) bool
+
+// ppr PageRank 迭代主体，调用方需持有读锁
+func (g *Graph) ppr(
+	seedWeights map[string]float64,
+	damping float64,
+	maxIter int,
+	tolerance float64,
+	spread spreadFunc,
+) map[string]float64 {
 	if len(seedWeights) == 0 {
 		return make(map[string]float64)
 	}
@@ -52,19 +129,11 @@ func (g *Graph) PPR(
 
 		// 对每个节点，将其分数分配给邻居
 		for nodeID, score := range scores {
-			neighbors := g.adjList[nodeID]
-
-			if len(neighbors) == 0 {
+			if !spread(nodeID, score, newScores) {
 				// 没有出边，分数回流到种子节点
 				for seedID, seedWeight := range normalizedSeeds {
 					newScores[seedID] += score * seedWeight
 				}
-			} else {
-				// 平均分配给邻居（可以根据边权重加权）
-				sharePerNeighbor := score / float64(len(neighbors))
-				for _, neighborID := range neighbors {
-					newScores[neighborID] += sharePerNeighbor
-				}
 			}
 		}
 
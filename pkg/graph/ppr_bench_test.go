@@ -0,0 +1,116 @@
+package graph
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// benchSizes 基准测试使用的文档块数量（实体数量相同）
+var benchSizes = []int{1000, 10000, 100000}
+
+// buildBenchGraph 构造与 HippoRAG.Index 结构相同的随机图：
+// 每个文档块连接 5 个实体（passage / passage_back），每个实体有 2 条事实边（fact / fact_back）
+func buildBenchGraph(numChunks int) (*Graph, map[string]float64) {
+	rng := rand.New(rand.NewSource(42))
+	g := NewGraph()
+
+	numEntities := numChunks
+	for i := 0; i < numEntities; i++ {
+		g.AddNode(fmt.Sprintf("e%d", i), "", "entity")
+	}
+	for i := 0; i < numChunks; i++ {
+		chunkID := fmt.Sprintf("c%d", i)
+		g.AddNode(chunkID, "", "chunk")
+		for j := 0; j < 5; j++ {
+			entityID := fmt.Sprintf("e%d", rng.Intn(numEntities))
+			g.AddEdge(chunkID, entityID, 1.0, "passage")
+			g.AddEdge(entityID, chunkID, 1.0, "passage_back")
+		}
+	}
+	for i := 0; i < numEntities; i++ {
+		for j := 0; j < 2; j++ {
+			from := fmt.Sprintf("e%d", i)
+			to := fmt.Sprintf("e%d", rng.Intn(numEntities))
+			g.AddEdge(from, to, 1.0, "fact")
+			g.AddEdge(to, from, 0.5, "fact_back")
+		}
+	}
+
+	seeds := make(map[string]float64)
+	for i := 0; i < 10; i++ {
+		seeds[fmt.Sprintf("e%d", rng.Intn(numEntities))] = rng.Float64()
+	}
+	return g, seeds
+}
+
+func BenchmarkPPRMap(b *testing.B) {
+	for _, n := range benchSizes {
+		g, seeds := buildBenchGraph(n)
+		b.Run(fmt.Sprintf("chunks=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				g.PPR(seeds, 0.5, 100, 1e-6)
+			}
+		})
+	}
+}
+
+func BenchmarkWeightedPPRMap(b *testing.B) {
+	for _, n := range benchSizes {
+		g, seeds := buildBenchGraph(n)
+		b.Run(fmt.Sprintf("chunks=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				g.WeightedPPR(seeds, 0.5, 100, 1e-6, nil)
+			}
+		})
+	}
+}
+
+func BenchmarkPPRCSR(b *testing.B) {
+	for _, n := range benchSizes {
+		g, seeds := buildBenchGraph(n)
+		csr := g.CSR()
+		b.Run(fmt.Sprintf("chunks=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				csr.UniformPPR(seeds, 0.5, 100, 1e-6)
+			}
+		})
+	}
+}
+
+func BenchmarkWeightedPPRCSR(b *testing.B) {
+	for _, n := range benchSizes {
+		g, seeds := buildBenchGraph(n)
+		csr := g.CSR()
+		b.Run(fmt.Sprintf("chunks=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				csr.PPR(seeds, 0.5, 100, 1e-6, nil)
+			}
+		})
+	}
+}
+
+func BenchmarkApproxPPR(b *testing.B) {
+	for _, n := range benchSizes {
+		g, seeds := buildBenchGraph(n)
+		g.CSR()
+		b.Run(fmt.Sprintf("chunks=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				g.ApproxPPR(seeds, 0.5, 1e-4, true, nil)
+			}
+		})
+	}
+}
+
+func BenchmarkCSRCompile(b *testing.B) {
+	for _, n := range benchSizes {
+		g, _ := buildBenchGraph(n)
+		b.Run(fmt.Sprintf("chunks=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				g.mu.RLock()
+				g.compile()
+				g.mu.RUnlock()
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package graph
+
+import (
+	"math"
+	"testing"
+)
+
+// buildWeightedGraph 手工可算的小图：
+// A -> B（fact，权重 3），A -> C（synonymy，权重 1），B、C 没有出边（悬挂节点，分数回流到种子 A）
+//
+// 以 A 为种子、damping = 0.5 时，稳态满足：
+//
+//	p(A) = 0.5 + 0.5 × (p(B) + p(C))
+//	p(B) = 0.5 × P(A→B) × p(A)
+//	p(C) = 0.5 × P(A→C) × p(A)
+//
+// p(B) + p(C) = 0.5 × p(A)，因此 p(A) = 2/3，与转移概率无关；p(B)、p(C) 按转移概率分配剩余的 1/3
+func buildWeightedGraph() *Graph {
+	g := NewGraph()
+	g.AddNode("A", "", "entity")
+	g.AddNode("B", "", "entity")
+	g.AddNode("C", "", "entity")
+	g.AddEdge("A", "B", 3, "fact")
+	g.AddEdge("A", "C", 1, "synonymy")
+	return g
+}
+
+// assertScores 检查分数与期望值一致（期望为 0 的节点允许不出现）
+func assertScores(t *testing.T, name string, got, want map[string]float64) {
+	t.Helper()
+	for id, w := range want {
+		if math.Abs(got[id]-w) > 1e-9 {
+			t.Errorf("%s: score[%s] = %.10f, want %.10f", name, id, got[id], w)
+		}
+	}
+}
+
+func TestWeightedPPR(t *testing.T) {
+	seeds := map[string]float64{"A": 1}
+
+	tests := []struct {
+		name        string
+		typeWeights map[string]float64
+		want        map[string]float64
+	}{
+		// 按边权重 3:1 分配
+		{"edge weights", nil, map[string]float64{"A": 2.0 / 3, "B": 1.0 / 4, "C": 1.0 / 12}},
+		// synonymy 乘数 3 后两条边的有效权重相同
+		{"type multiplier", map[string]float64{"synonymy": 3}, map[string]float64{"A": 2.0 / 3, "B": 1.0 / 6, "C": 1.0 / 6}},
+		// 乘数为 0 的边类型不参与传播
+		{"type disabled", map[string]float64{"synonymy": 0}, map[string]float64{"A": 2.0 / 3, "B": 1.0 / 3, "C": 0}},
+	}
+
+	g := buildWeightedGraph()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertScores(t, "WeightedPPR", g.WeightedPPR(seeds, 0.5, 200, 1e-12, tt.typeWeights), tt.want)
+			assertScores(t, "CSR.PPR", g.CSR().PPR(seeds, 0.5, 200, 1e-12, tt.typeWeights), tt.want)
+		})
+	}
+}
+
+func TestUniformPPR(t *testing.T) {
+	// 平均分配时忽略边权重
+	seeds := map[string]float64{"A": 1}
+	want := map[string]float64{"A": 2.0 / 3, "B": 1.0 / 6, "C": 1.0 / 6}
+
+	g := buildWeightedGraph()
+	assertScores(t, "PPR", g.PPR(seeds, 0.5, 200, 1e-12), want)
+	assertScores(t, "CSR.UniformPPR", g.CSR().UniformPPR(seeds, 0.5, 200, 1e-12), want)
+}
+
+func TestEffectiveWeight(t *testing.T) {
+	typeWeights := map[string]float64{"fact": 0.5, "synonymy": -1}
+
+	tests := []struct {
+		edge Edge
+		want float64
+	}{
+		{Edge{Weight: 2, Type: "fact"}, 1},       // 乘以类型乘数
+		{Edge{Weight: 2, Type: "passage"}, 2},    // 未设置的类型乘数为 1
+		{Edge{Weight: 0.9, Type: "synonymy"}, 0}, // 负权重按 0 处理
+		{Edge{Weight: -1, Type: "passage"}, 0},
+	}
+
+	for _, tt := range tests {
+		edge := tt.edge
+		if got := EffectiveWeight(&edge, typeWeights); got != tt.want {
+			t.Errorf("EffectiveWeight(%+v) = %v, want %v", tt.edge, got, tt.want)
+		}
+	}
+	if got := EffectiveWeight(&Edge{Weight: 2, Type: "fact"}, nil); got != 2 {
+		t.Errorf("EffectiveWeight without multipliers = %v, want 2", got)
+	}
+}
@@ -0,0 +1,139 @@
+package graph
+
+// push.go - 基于局部推送的近似 Personalized PageRank
+// 用途：交互式查询只需要种子实体附近的高分节点，无需对全图做幂迭代
+// 算法：Andersen–Chung–Lang forward push
+// - 每个节点维护估计值 p 和残差 r，初始时残差等于种子分布
+// - 残差超过 epsilon × 出度 的节点把 (1-damping) 比例的残差转入 p，其余按转移概率推给邻居
+// - 只访问被推送触及的节点，计算量与局部邻域大小相关，而非整张图
+
+// ApproxPPR 执行近似 Personalized PageRank（在 CSR 快照上）
+// seedWeights: 种子节点及其初始权重
+// damping: 阻尼系数，含义与 PPR 相同
+// epsilon: 残差阈值，越小越精确、触及的节点越多；必须大于 0，否则返回空结果
+// weighted: 是否按边权重传播（false 时平均分配）
+// typeWeights: 边类型权重乘数（仅 weighted 时生效）
+// 返回：被触及节点的近似分数
+func (g *Graph) ApproxPPR(
+	seedWeights map[string]float64,
+	damping float64,
+	epsilon float64,
+	weighted bool,
+	typeWeights map[string]float64,
+) map[string]float64 {
+	return g.CSR().ApproxPPR(seedWeights, damping, epsilon, weighted, typeWeights)
+}
+
+// ApproxPPR 在 CSR 快照上执行近似 Personalized PageRank，参数含义与 Graph.ApproxPPR 相同
+// 悬挂节点的推送量按种子分布回流，与 PPR 一致
+func (c *CSR) ApproxPPR(
+	seedWeights map[string]float64,
+	damping float64,
+	epsilon float64,
+	weighted bool,
+	typeWeights map[string]float64,
+) map[string]float64 {
+	result := make(map[string]float64)
+	if epsilon <= 0 {
+		return result
+	}
+
+	// 归一化种子（只保留图中存在的节点）
+	seeds := make(map[int]float64)
+	total := 0.0
+	for id, weight := range seedWeights {
+		if i, exists := c.index[id]; exists && weight > 0 {
+			seeds[i] += weight
+			total += weight
+		}
+	}
+	if total == 0 {
+		return result
+	}
+	for i := range seeds {
+		seeds[i] /= total
+	}
+
+	estimates := make(map[int]float64)
+	residuals := make(map[int]float64)
+	inQueue := make(map[int]bool)
+	var queue []int
+
+	// threshold 节点残差需要达到的推送阈值
+	threshold := func(u int) float64 {
+		degree := c.rowPtr[u+1] - c.rowPtr[u]
+		if degree < 1 {
+			degree = 1
+		}
+		return epsilon * float64(degree)
+	}
+	// addResidual 增加残差，超过阈值时加入队列
+	addResidual := func(v int, amount float64) {
+		residuals[v] += amount
+		if !inQueue[v] && residuals[v] >= threshold(v) {
+			inQueue[v] = true
+			queue = append(queue, v)
+		}
+	}
+
+	for i, weight := range seeds {
+		addResidual(i, weight)
+	}
+
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		inQueue[u] = false
+
+		ru := residuals[u]
+		if ru < threshold(u) {
+			continue
+		}
+
+		estimates[u] += (1 - damping) * ru
+		residuals[u] = 0
+		pushed := damping * ru
+
+		// 按需计算该行的转移权重
+		start, end := c.rowPtr[u], c.rowPtr[u+1]
+		rowTotal := 0.0
+		for k := start; k < end; k++ {
+			rowTotal += c.edgeWeight(k, weighted, typeWeights)
+		}
+
+		if rowTotal <= 0 {
+			// 悬挂节点：回流到种子节点
+			for i, weight := range seeds {
+				addResidual(i, pushed*weight)
+			}
+			continue
+		}
+
+		for k := start; k < end; k++ {
+			if w := c.edgeWeight(k, weighted, typeWeights); w > 0 {
+				addResidual(c.colIdx[k], pushed*w/rowTotal)
+			}
+		}
+	}
+
+	for i, score := range estimates {
+		result[c.ids[i]] = score
+	}
+	return result
+}
+
+// edgeWeight 返回第 k 条边在传播时的（未归一化）权重
+func (c *CSR) edgeWeight(k int, weighted bool, typeWeights map[string]float64) float64 {
+	if !weighted {
+		return 1.0
+	}
+
+	weight := c.weights[k]
+	if multiplier, exists := typeWeights[c.typeNames[c.edgeTypes[k]]]; exists {
+		weight *= multiplier
+	}
+	if weight < 0 {
+		return 0
+	}
+	return weight
+}
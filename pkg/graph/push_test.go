@@ -0,0 +1,63 @@
+package graph
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// TestApproxPPRErrorBound 近似 PPR 与精确 PPR 的误差不超过 epsilon × 度数
+// 在无向图（每条边双向）上，forward push 结束时每个节点的残差小于 epsilon × 度数，
+// 由 PPR 的对称性可得 0 <= 精确值 - 近似值 < epsilon × 度数
+func TestApproxPPRErrorBound(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	g := NewGraph()
+
+	const n = 200
+	for i := 0; i < n; i++ {
+		g.AddNode(fmt.Sprintf("n%d", i), "", "entity")
+	}
+	addUndirected := func(a, b int) {
+		if a == b {
+			return
+		}
+		g.AddEdge(fmt.Sprintf("n%d", a), fmt.Sprintf("n%d", b), 1.0, "fact")
+		g.AddEdge(fmt.Sprintf("n%d", b), fmt.Sprintf("n%d", a), 1.0, "fact")
+	}
+	// 环保证没有悬挂节点，再加随机弦
+	for i := 0; i < n; i++ {
+		addUndirected(i, (i+1)%n)
+	}
+	for i := 0; i < 3*n; i++ {
+		addUndirected(rng.Intn(n), rng.Intn(n))
+	}
+
+	seeds := map[string]float64{"n0": 2, "n17": 1, "n150": 1}
+	csr := g.CSR()
+	exact := csr.UniformPPR(seeds, 0.5, 1000, 1e-15)
+
+	for _, epsilon := range []float64{1e-3, 1e-4, 1e-6} {
+		approx := csr.ApproxPPR(seeds, 0.5, epsilon, false, nil)
+		if len(approx) == 0 {
+			t.Fatalf("epsilon %g: empty result", epsilon)
+		}
+
+		for i := 0; i < csr.NodeCount(); i++ {
+			id := csr.ID(i)
+			degree := len(g.GetNeighbors(id))
+			diff := exact[id] - approx[id]
+			if diff < -1e-12 || diff > epsilon*float64(degree) {
+				t.Errorf("epsilon %g: node %s exact %.10f approx %.10f (bound %.10f)",
+					epsilon, id, exact[id], approx[id], epsilon*float64(degree))
+			}
+		}
+	}
+}
+
+// TestApproxPPRNonPositiveEpsilon epsilon <= 0 时返回空结果（由调用方设置默认值）
+func TestApproxPPRNonPositiveEpsilon(t *testing.T) {
+	g := buildWeightedGraph()
+	if scores := g.ApproxPPR(map[string]float64{"A": 1}, 0.5, 0, true, nil); len(scores) != 0 {
+		t.Errorf("epsilon 0: got %v, want empty", scores)
+	}
+}
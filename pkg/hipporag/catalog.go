@@ -0,0 +1,328 @@
+package hipporag
+
+// catalog.go - 索引来源关系
+// 用途：记录 文档 -> 文档块 -> 实体/事实 的来源关系
+// 主要功能：
+// - 索引时登记每个文档块属于哪些文档（及在文档中的位置）、抽取出了哪些实体和事实
+// - 检索时查询文档块的来源文档和元数据
+// - 删除文档时计算需要回收的文档块、实体，以及失去来源的事实（由 FactStore 判断事实是否仍被引用）
+// - 随索引一起保存到 catalog.json
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/example/go-scaffold/pkg/document"
+	"github.com/example/go-scaffold/pkg/utils"
+)
+
+// DocumentID 返回文档文本对应的文档 ID（基于内容哈希）
+// 调用方未指定文档 ID 时使用
+func DocumentID(doc string) string {
+	return document.ID(doc)
+}
+
+// chunkSource 文档块在某个文档中的位置
+type chunkSource struct {
+	DocID string `json:"doc_id"`
+	Start int    `json:"start"` // 起始字节偏移
+	End   int    `json:"end"`   // 结束字节偏移（不含）
+}
+
+// catalog 索引来源关系（并发安全）
+type catalog struct {
+	mu sync.RWMutex
+
+	docChunks     map[string][]string          // 文档 ID -> 文档块 ID（按文档中的顺序）
+	docMetadata   map[string]map[string]string // 文档 ID -> 元数据
+	chunkDocs     map[string][]chunkSource     // 文档块 ID -> 来源（按索引顺序）
+	chunkEntities map[string][]string          // 文档块 ID -> 从该块抽取的实体
+	chunkFacts    map[string][]string          // 文档块 ID -> 从该块抽取的事实
+	entityChunks  map[string]map[string]bool   // 实体 ID -> 支撑该实体的文档块
+}
+
+func newCatalog() *catalog {
+	return &catalog{
+		docChunks:     make(map[string][]string),
+		docMetadata:   make(map[string]map[string]string),
+		chunkDocs:     make(map[string][]chunkSource),
+		chunkEntities: make(map[string][]string),
+		chunkFacts:    make(map[string][]string),
+		entityChunks:  make(map[string]map[string]bool),
+	}
+}
+
+// hasDocument 判断文档是否已登记
+func (c *catalog) hasDocument(docID string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	_, exists := c.docChunks[docID]
+	return exists
+}
+
+// addDocument 登记文档包含的文档块及其位置
+func (c *catalog) addDocument(docID string, metadata map[string]string, chunkIDs []string, spans []utils.TextChunk) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.docChunks[docID] = chunkIDs
+	if len(metadata) > 0 {
+		c.docMetadata[docID] = metadata
+	}
+
+	for i, chunkID := range chunkIDs {
+		// 同一文档中重复出现的文档块只记录第一次出现的位置
+		duplicate := false
+		for _, source := range c.chunkDocs[chunkID] {
+			if source.DocID == docID {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			c.chunkDocs[chunkID] = append(c.chunkDocs[chunkID], chunkSource{
+				DocID: docID,
+				Start: spans[i].Start,
+				End:   spans[i].End,
+			})
+		}
+	}
+}
+
+// source 返回文档块的主来源（最早索引的文档）及该文档的元数据
+func (c *catalog) source(chunkID string) (chunkSource, map[string]string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	sources := c.chunkDocs[chunkID]
+	if len(sources) == 0 {
+		return chunkSource{}, nil, false
+	}
+	return sources[0], c.docMetadata[sources[0].DocID], true
+}
+
+// addChunk 登记从文档块中抽取的实体和事实
+func (c *catalog) addChunk(chunkID string, entityIDs []string, factIDs []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.chunkEntities[chunkID] = entityIDs
+	for _, entityID := range entityIDs {
+		addToSet(c.entityChunks, entityID, chunkID)
+	}
+
+	c.chunkFacts[chunkID] = factIDs
+}
+
+// documents 返回所有文档 ID（排序）
+func (c *catalog) documents() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ids := make([]string, 0, len(c.docChunks))
+	for id := range c.docChunks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// removal 删除文档后需要回收的内容
+type removal struct {
+	chunks   []string  // 不再属于任何文档的文档块
+	entities []string  // 不再有文档块支撑的实体
+	facts    []factRef // 被删除的文档块抽取出的事实（是否回收由 FactStore 判断）
+}
+
+// factRef 事实与抽取出它的文档块
+type factRef struct {
+	factID  string
+	chunkID string
+}
+
+// removeDocuments 删除文档并计算需要回收的内容
+// 任一文档不存在时返回错误，且不做任何修改
+func (c *catalog) removeDocuments(docIDs []string) (*removal, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, docID := range docIDs {
+		if _, exists := c.docChunks[docID]; !exists {
+			return nil, fmt.Errorf("document not found: %s", docID)
+		}
+	}
+
+	result := &removal{}
+
+	// 1. 文档 -> 文档块
+	for _, docID := range docIDs {
+		for _, chunkID := range c.docChunks[docID] {
+			if c.removeSource(chunkID, docID) {
+				result.chunks = append(result.chunks, chunkID)
+			}
+		}
+		delete(c.docChunks, docID)
+		delete(c.docMetadata, docID)
+	}
+
+	// 2. 文档块 -> 实体 / 事实
+	for _, chunkID := range result.chunks {
+		for _, entityID := range c.chunkEntities[chunkID] {
+			if removeFromSet(c.entityChunks, entityID, chunkID) {
+				result.entities = append(result.entities, entityID)
+			}
+		}
+		for _, factID := range c.chunkFacts[chunkID] {
+			result.facts = append(result.facts, factRef{factID: factID, chunkID: chunkID})
+		}
+		delete(c.chunkEntities, chunkID)
+		delete(c.chunkFacts, chunkID)
+	}
+
+	return result, nil
+}
+
+// removeSource 删除文档块来自 docID 的来源，没有剩余来源时删除记录并返回 true
+func (c *catalog) removeSource(chunkID, docID string) bool {
+	sources, exists := c.chunkDocs[chunkID]
+	if !exists {
+		return false
+	}
+
+	remaining := make([]chunkSource, 0, len(sources))
+	for _, source := range sources {
+		if source.DocID != docID {
+			remaining = append(remaining, source)
+		}
+	}
+	if len(remaining) == 0 {
+		delete(c.chunkDocs, chunkID)
+		return true
+	}
+	c.chunkDocs[chunkID] = remaining
+	return false
+}
+
+// pairKey 无序实体对：(A, B) 和 (B, A) 共用同一对 fact / fact_back 边
+func pairKey(a, b string) [2]string {
+	if a > b {
+		return [2]string{b, a}
+	}
+	return [2]string{a, b}
+}
+
+// addToSet 向 sets[key] 添加 value
+func addToSet(sets map[string]map[string]bool, key, value string) {
+	if sets[key] == nil {
+		sets[key] = make(map[string]bool)
+	}
+	sets[key][value] = true
+}
+
+// removeFromSet 从 sets[key] 删除 value，集合变为空时删除 key 并返回 true
+func removeFromSet(sets map[string]map[string]bool, key, value string) bool {
+	set, exists := sets[key]
+	if !exists {
+		return false
+	}
+
+	delete(set, value)
+	if len(set) == 0 {
+		delete(sets, key)
+		return true
+	}
+	return false
+}
+
+type catalogData struct {
+	DocChunks     map[string][]string          `json:"doc_chunks"`
+	DocMetadata   map[string]map[string]string `json:"doc_metadata"`
+	ChunkDocs     map[string][]chunkSource     `json:"chunk_docs"`
+	ChunkEntities map[string][]string          `json:"chunk_entities"`
+	ChunkFacts    map[string][]string          `json:"chunk_facts"`
+	EntityChunks  map[string]map[string]bool   `json:"entity_chunks"`
+}
+
+// save 保存到文件
+func (c *catalog) save(path string) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	data := catalogData{
+		DocChunks:     c.docChunks,
+		DocMetadata:   c.docMetadata,
+		ChunkDocs:     c.chunkDocs,
+		ChunkEntities: c.chunkEntities,
+		ChunkFacts:    c.chunkFacts,
+		EntityChunks:  c.entityChunks,
+	}
+
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal data: %w", err)
+	}
+
+	if err := os.WriteFile(path, jsonData, 0644); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+
+	return nil
+}
+
+// load 从文件加载
+func (c *catalog) load(path string) error {
+	jsonData, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+
+	var data catalogData
+	if err := json.Unmarshal(jsonData, &data); err != nil {
+		return fmt.Errorf("unmarshal data: %w", err)
+	}
+
+	loaded := newCatalog()
+	for id, chunks := range data.DocChunks {
+		loaded.docChunks[id] = chunks
+	}
+	for id, metadata := range data.DocMetadata {
+		loaded.docMetadata[id] = metadata
+	}
+	for id, sources := range data.ChunkDocs {
+		loaded.chunkDocs[id] = sources
+	}
+	for id, entities := range data.ChunkEntities {
+		loaded.chunkEntities[id] = entities
+	}
+	for id, facts := range data.ChunkFacts {
+		loaded.chunkFacts[id] = facts
+	}
+	for id, chunks := range data.EntityChunks {
+		loaded.entityChunks[id] = chunks
+	}
+
+	c.replace(loaded)
+	return nil
+}
+
+// reset 清空全部来源关系
+func (c *catalog) reset() {
+	c.replace(newCatalog())
+}
+
+// replace 用 other 的内容替换当前内容
+func (c *catalog) replace(other *catalog) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.docChunks = other.docChunks
+	c.docMetadata = other.docMetadata
+	c.chunkDocs = other.chunkDocs
+	c.chunkEntities = other.chunkEntities
+	c.chunkFacts = other.chunkFacts
+	c.entityChunks = other.entityChunks
+}
@@ -0,0 +1,105 @@
+package hipporag
+
+// delete.go - 文档删除与图谱垃圾回收
+// 用途：从索引中删除文档
+// 删除流程：
+// 1. 删除只属于这些文档的文档块（连同 passage 边）
+// 2. 删除只从这些文档块中抽取的事实，以及不再有事实支撑的 fact / fact_back 边（synonymy 边不依赖事实，保留）
+// 3. 删除不再有任何文档块支撑的实体（连同其所有边）；失去 fact 边的同义实体对重新连接 synonymy 边
+// 4. 同步删除文档块、实体、事实向量存储中的对应条目
+
+import (
+	"context"
+	"fmt"
+)
+
+// Documents 返回已索引的文档 ID（排序）
+func (h *HippoRAG) Documents() []string {
+	return h.catalog.documents()
+}
+
+// DeleteDocuments 删除文档
+// docIDs: 文档 ID（索引时指定的 ID，未指定时见 DocumentID）；任一文档不存在时返回错误且不做任何删除
+func (h *HippoRAG) DeleteDocuments(ctx context.Context, docIDs []string) error {
+	h.indexMu.Lock()
+	defer h.indexMu.Unlock()
+
+	plan, err := h.catalog.removeDocuments(docIDs)
+	if err != nil {
+		return err
+	}
+
+	// 事实失去所有来源文档块后回收，记录受影响的实体对
+	var facts []string
+	affectedPairs := make(map[[2]string]bool)
+	for _, ref := range plan.facts {
+		fact, exists := h.facts.Get(ref.factID)
+		if !exists {
+			continue
+		}
+		if h.facts.detachChunk(ref.factID, ref.chunkID) {
+			facts = append(facts, ref.factID)
+			affectedPairs[pairKey(fact.SubjectID, fact.ObjectID)] = true
+		}
+	}
+
+	// 先更新图谱，检索不会再访问到被删除的节点
+	for _, chunkID := range plan.chunks {
+		h.graph.RemoveNode(chunkID)
+	}
+	var unlinked [][2]string
+	for pair := range affectedPairs {
+		// 仍有其他事实连接的实体对保留 fact 边，边的方向按剩余的事实重新设置
+		if h.facts.connects(pair[0], pair[1]) {
+			h.linkFactPair(h.graph, pair[0], pair[1])
+			continue
+		}
+		forward := h.removeFactEdge(pair[0], pair[1])
+		backward := h.removeFactEdge(pair[1], pair[0])
+		if forward || backward {
+			unlinked = append(unlinked, pair)
+		}
+	}
+	for _, entityID := range plan.entities {
+		h.graph.RemoveNode(entityID)
+	}
+
+	// 再同步删除向量存储
+	for _, chunkID := range plan.chunks {
+		if err := h.chunkStore.Delete(ctx, chunkID); err != nil {
+			return fmt.Errorf("delete chunk %s: %w", chunkID, err)
+		}
+	}
+	for _, factID := range facts {
+		if err := h.facts.Delete(ctx, factID); err != nil {
+			return fmt.Errorf("delete fact %s: %w", factID, err)
+		}
+	}
+	for _, entityID := range plan.entities {
+		if err := h.entityStore.Delete(ctx, entityID); err != nil {
+			return fmt.Errorf("delete entity %s: %w", entityID, err)
+		}
+	}
+
+	// 失去事实边、两端仍存在的实体对按相似度恢复同义边
+	if _, err := h.relinkSynonyms(ctx, unlinked); err != nil {
+		return fmt.Errorf("relink synonyms: %w", err)
+	}
+
+	fmt.Printf("Deleted %d documents: %d chunks, %d facts, %d entities removed\n",
+		len(docIDs), len(plan.chunks), len(facts), len(plan.entities))
+	fmt.Printf("  Graph: %d nodes, %d edges\n", h.graph.NodeCount(), h.graph.EdgeCount())
+
+	return nil
+}
+
+// removeFactEdge 删除 from -> to 的 fact / fact_back 边，同一实体对上的其他类型的边（如 synonymy）保留
+// 返回是否删除了边；调用方需持有 indexMu
+func (h *HippoRAG) removeFactEdge(from, to string) bool {
+	edge, exists := h.graph.GetEdge(from, to)
+	if !exists || (edge.Type != "fact" && edge.Type != "fact_back") {
+		return false
+	}
+	h.graph.RemoveEdge(from, to)
+	return true
+}
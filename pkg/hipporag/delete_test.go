@@ -0,0 +1,173 @@
+package hipporag
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/example/go-scaffold/pkg/document"
+	"github.com/example/go-scaffold/pkg/embedding"
+	"github.com/example/go-scaffold/pkg/graph"
+	"github.com/example/go-scaffold/pkg/openie"
+	"github.com/example/go-scaffold/pkg/utils"
+)
+
+// newOfflineHippoRAG 创建不依赖网络的 HippoRAG：本地 embedding + 规则抽取器
+// 同义边阈值 0.6 时，测试语料中只有 "爱因斯坦" 与 "阿尔伯特·爱因斯坦" 相似
+func newOfflineHippoRAG() *HippoRAG {
+	config := DefaultConfig()
+	config.SynonymyThreshold = 0.6
+
+	h := NewHippoRAG(config, embedding.NewLocalClient(0), nil)
+	h.SetExtractor(openie.NewRuleExtractor())
+	return h
+}
+
+// entityNodeID 实体文本对应的节点 ID（与实体向量存储的 ID 规则相同）
+func entityNodeID(entity string) string {
+	return utils.Hash(entity)[:16]
+}
+
+// edgeType 返回 from -> to 的边类型，没有边时返回空字符串
+func edgeType(h *HippoRAG, from, to string) string {
+	edge, exists := h.graph.GetEdge(entityNodeID(from), entityNodeID(to))
+	if !exists {
+		return ""
+	}
+	return edge.Type
+}
+
+// assertConsistent 检查图谱、向量存储、事实存储和来源关系互相一致
+func assertConsistent(t *testing.T, h *HippoRAG) {
+	t.Helper()
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	if err := h.graph.Save(&buf); err != nil {
+		t.Fatalf("save graph: %v", err)
+	}
+	var snapshot struct {
+		Nodes []graph.Node `json:"nodes"`
+		Edges []graph.Edge `json:"edges"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &snapshot); err != nil {
+		t.Fatalf("decode graph: %v", err)
+	}
+
+	nodes := make(map[string]string) // 节点 ID -> 类型
+	counts := make(map[string]int)
+	for _, node := range snapshot.Nodes {
+		nodes[node.ID] = node.Type
+		counts[node.Type]++
+
+		store := h.entityStore
+		if node.Type == "chunk" {
+			store = h.chunkStore
+		}
+		if _, err := store.GetContent(ctx, node.ID); err != nil {
+			t.Errorf("%s node %s has no vector: %v", node.Type, node.ID, err)
+		}
+	}
+	stats := h.Stats(ctx)
+	if stats["chunks"] != counts["chunk"] || stats["entities"] != counts["entity"] {
+		t.Errorf("stores have %d chunks and %d entities, graph has %d and %d",
+			stats["chunks"], stats["entities"], counts["chunk"], counts["entity"])
+	}
+
+	for _, edge := range snapshot.Edges {
+		if nodes[edge.From] == "" || nodes[edge.To] == "" {
+			t.Errorf("%s edge %s -> %s has a missing endpoint", edge.Type, edge.From, edge.To)
+		}
+		if (edge.Type == "fact" || edge.Type == "fact_back") && !h.facts.connects(edge.From, edge.To) {
+			t.Errorf("%s edge %s -> %s has no supporting fact", edge.Type, edge.From, edge.To)
+		}
+	}
+
+	h.facts.mu.RLock()
+	defer h.facts.mu.RUnlock()
+	for _, fact := range h.facts.facts {
+		if len(fact.ChunkIDs) == 0 {
+			t.Errorf("fact %q has no source chunk", fact.Text())
+		}
+		for _, chunkID := range fact.ChunkIDs {
+			if nodes[chunkID] != "chunk" {
+				t.Errorf("fact %q references deleted chunk %s", fact.Text(), chunkID)
+			}
+		}
+		if nodes[fact.SubjectID] != "entity" || nodes[fact.ObjectID] != "entity" {
+			t.Errorf("fact %q references a deleted entity", fact.Text())
+		}
+		if _, err := h.facts.vectors.GetContent(ctx, fact.VectorID); err != nil {
+			t.Errorf("fact %q has no vector: %v", fact.Text(), err)
+		}
+	}
+}
+
+// TestDeleteDocumentsSharedEntitiesAndFacts 删除文档时保留仍被其他文档支撑的实体、事实和边
+func TestDeleteDocumentsSharedEntitiesAndFacts(t *testing.T) {
+	ctx := context.Background()
+	h := newOfflineHippoRAG()
+
+	docs := []document.Document{
+		// a 和 b 共享实体 爱因斯坦、阿尔伯特·爱因斯坦
+		{ID: "a", Text: "爱因斯坦是阿尔伯特·爱因斯坦。"},
+		{ID: "b", Text: "爱因斯坦提出了相对论。阿尔伯特·爱因斯坦出生于乌尔姆。"},
+		// c 和 d 共享事实 (居里夫人, 发现了, 镭)
+		{ID: "c", Text: "居里夫人发现了镭。"},
+		{ID: "d", Text: "居里夫人发现了镭。居里夫人获得了诺贝尔奖。"},
+	}
+	if err := h.Insert(ctx, docs); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	assertConsistent(t, h)
+	factsBefore := h.facts.Size()
+
+	// 删除 a：两个实体仍由 b 支撑，只回收 a 独有的事实，同义边保留
+	if err := h.DeleteDocuments(ctx, []string{"a"}); err != nil {
+		t.Fatalf("DeleteDocuments(a): %v", err)
+	}
+	assertConsistent(t, h)
+	for _, entity := range []string{"爱因斯坦", "阿尔伯特·爱因斯坦", "相对论", "乌尔姆"} {
+		if _, exists := h.graph.GetNode(entityNodeID(entity)); !exists {
+			t.Errorf("entity %s was removed but is still supported by document b", entity)
+		}
+	}
+	if h.facts.Size() != factsBefore-1 {
+		t.Errorf("facts: got %d, want %d", h.facts.Size(), factsBefore-1)
+	}
+	if got, back := edgeType(h, "爱因斯坦", "阿尔伯特·爱因斯坦"), edgeType(h, "阿尔伯特·爱因斯坦", "爱因斯坦"); got != "synonymy" || back != "synonymy" {
+		t.Errorf("edges between synonyms: got %q / %q, want synonymy in both directions", got, back)
+	}
+
+	// 删除 c：共享的事实仍由 d 支撑
+	if err := h.DeleteDocuments(ctx, []string{"c"}); err != nil {
+		t.Fatalf("DeleteDocuments(c): %v", err)
+	}
+	assertConsistent(t, h)
+	if got := edgeType(h, "居里夫人", "镭"); got != "fact" {
+		t.Errorf("shared fact edge after deleting c: got %q, want fact", got)
+	}
+
+	// 删除 d：事实和只由 d 支撑的实体全部回收
+	if err := h.DeleteDocuments(ctx, []string{"d"}); err != nil {
+		t.Fatalf("DeleteDocuments(d): %v", err)
+	}
+	assertConsistent(t, h)
+	for _, entity := range []string{"居里夫人", "镭", "诺贝尔奖"} {
+		if _, exists := h.graph.GetNode(entityNodeID(entity)); exists {
+			t.Errorf("entity %s is still in the graph after deleting all its documents", entity)
+		}
+	}
+	if docs := h.Documents(); len(docs) != 1 || docs[0] != "b" {
+		t.Errorf("Documents: got %v, want [b]", docs)
+	}
+
+	// 不存在的文档：返回错误且不做任何删除
+	if err := h.DeleteDocuments(ctx, []string{"b", "missing"}); err == nil {
+		t.Error("DeleteDocuments with a missing document: want error")
+	}
+	if docs := h.Documents(); len(docs) != 1 {
+		t.Errorf("Documents after failed delete: got %v", docs)
+	}
+}
@@ -0,0 +1,323 @@
+package hipporag
+
+// facts.go - 结构化事实存储
+// 用途：保存 OpenIE 抽取的 (主语, 谓语, 宾语) 三元组，并关联图谱中的实体节点和来源文档块
+// 主要功能：
+// - Insert: 插入事实（文本形式写入向量存储用于相似度检索，三元组结构单独保存）
+// - Search: 向量检索相关事实，直接返回结构化三元组
+// - Get: 根据 ID 获取事实
+// - 事实 ID 由三个字段组成的 JSON 数组哈希得到，("a b", "c", "d") 和 ("a", "b c", "d") 是不同的事实；
+//   文本形式相同的事实共用一个向量
+// - 按实体对索引事实，删除文档时无需遍历全部事实
+// - 检索时用事实的 SubjectID / ObjectID 作为 PPR 种子，无需再从文本中解析实体
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/example/go-scaffold/pkg/embedding"
+	"github.com/example/go-scaffold/pkg/utils"
+)
+
+// Fact 结构化事实
+type Fact struct {
+	ID        string   `json:"id"`
+	Subject   string   `json:"subject"`
+	Predicate string   `json:"predicate"`
+	Object    string   `json:"object"`
+	SubjectID string   `json:"subject_id"` // 主语实体节点 ID
+	ObjectID  string   `json:"object_id"`  // 宾语实体节点 ID
+	ChunkIDs  []string `json:"chunk_ids"`  // 抽取出该事实的文档块
+	VectorID  string   `json:"vector_id"`  // 事实文本在向量存储中的 ID
+}
+
+// factID 根据三元组生成事实 ID（按 JSON 数组哈希，字段中的空格不会造成混淆）
+func factID(subject, predicate, object string) string {
+	data, _ := json.Marshal([3]string{subject, predicate, object})
+	return "fact-" + utils.Hash(string(data))[:16]
+}
+
+// Text 事实的文本形式（用于向量化和展示）
+func (f *Fact) Text() string {
+	return fmt.Sprintf("%s %s %s", f.Subject, f.Predicate, f.Object)
+}
+
+// FactStore 结构化事实存储（并发安全）
+type FactStore struct {
+	vectors embedding.VectorStore  // 事实文本的向量存储
+	facts   map[string]*Fact       // 事实 ID -> 三元组
+	byText  map[string][]string    // 向量 ID -> 文本形式相同的事实 ID
+	byPair  map[[2]string][]string // 实体对（见 pairKey）-> 连接它们的事实 ID
+	mu      sync.RWMutex
+}
+
+// NewFactStore 创建结构化事实存储
+// vectors: 保存事实文本向量的存储（内存或 Weaviate）
+func NewFactStore(vectors embedding.VectorStore) *FactStore {
+	s := &FactStore{vectors: vectors}
+	s.reset()
+	return s
+}
+
+// Insert 插入事实，返回每个事实的 ID
+// 三元组相同的事实共用同一个 ID，来源文档块会合并
+func (s *FactStore) Insert(ctx context.Context, facts []Fact) ([]string, error) {
+	if len(facts) == 0 {
+		return []string{}, nil
+	}
+
+	texts := make([]string, len(facts))
+	for i := range facts {
+		texts[i] = facts[i].Text()
+	}
+
+	ids, err := s.vectors.Insert(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	factIDs := make([]string, len(facts))
+	for i, vectorID := range ids {
+		id := factID(facts[i].Subject, facts[i].Predicate, facts[i].Object)
+		factIDs[i] = id
+
+		existing, exists := s.facts[id]
+		if !exists {
+			fact := facts[i]
+			fact.ID = id
+			fact.VectorID = vectorID
+			fact.ChunkIDs = append([]string(nil), fact.ChunkIDs...)
+			s.add(&fact)
+			continue
+		}
+
+		for _, chunkID := range facts[i].ChunkIDs {
+			if !containsString(existing.ChunkIDs, chunkID) {
+				existing.ChunkIDs = append(existing.ChunkIDs, chunkID)
+			}
+		}
+	}
+
+	return factIDs, nil
+}
+
+// add 保存事实并更新索引（调用方持有写锁）
+func (s *FactStore) add(fact *Fact) {
+	s.facts[fact.ID] = fact
+	s.byText[fact.VectorID] = append(s.byText[fact.VectorID], fact.ID)
+	pair := pairKey(fact.SubjectID, fact.ObjectID)
+	s.byPair[pair] = append(s.byPair[pair], fact.ID)
+}
+
+// Get 根据 ID 获取事实
+func (s *FactStore) Get(id string) (*Fact, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	fact, exists := s.facts[id]
+	return fact, exists
+}
+
+// Search 向量检索最相关的事实
+// 返回：事实列表和对应的相似度分数（向量存储中没有三元组结构的条目会被跳过；
+// 文本相同的多个事实得分相同，按 ID 顺序返回，总数不超过 topK）
+func (s *FactStore) Search(ctx context.Context, queryVec []float64, topK int) ([]*Fact, []float64, error) {
+	ids, scores, err := s.vectors.Search(ctx, queryVec, topK)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	facts := make([]*Fact, 0, len(ids))
+	factScores := make([]float64, 0, len(ids))
+	for i, vectorID := range ids {
+		for _, id := range s.byText[vectorID] {
+			if len(facts) == topK {
+				return facts, factScores, nil
+			}
+			facts = append(facts, s.facts[id])
+			factScores = append(factScores, scores[i])
+		}
+	}
+
+	return facts, factScores, nil
+}
+
+// detachChunk 移除事实的一个来源文档块，移除后事实不再有任何来源时返回 true
+func (s *FactStore) detachChunk(id, chunkID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fact, exists := s.facts[id]
+	if !exists {
+		return false
+	}
+
+	remaining := make([]string, 0, len(fact.ChunkIDs))
+	for _, existing := range fact.ChunkIDs {
+		if existing != chunkID {
+			remaining = append(remaining, existing)
+		}
+	}
+	if len(remaining) == len(fact.ChunkIDs) {
+		return false
+	}
+	fact.ChunkIDs = remaining
+	return len(remaining) == 0
+}
+
+// connects 判断是否还有仍有来源的事实连接实体 a 和 b（任一方向）
+func (s *FactStore) connects(a, b string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, id := range s.byPair[pairKey(a, b)] {
+		if len(s.facts[id].ChunkIDs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// links 判断是否还有仍有来源的事实以 subjectID 为主语、objectID 为宾语
+func (s *FactStore) links(subjectID, objectID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, id := range s.byPair[pairKey(subjectID, objectID)] {
+		fact := s.facts[id]
+		if len(fact.ChunkIDs) > 0 && fact.SubjectID == subjectID && fact.ObjectID == objectID {
+			return true
+		}
+	}
+	return false
+}
+
+// Delete 删除事实；没有其他事实共用该文本时同时删除向量
+func (s *FactStore) Delete(ctx context.Context, id string) error {
+	s.mu.RLock()
+	fact, exists := s.facts[id]
+	shared := exists && len(s.byText[fact.VectorID]) > 1
+	s.mu.RUnlock()
+	if !exists {
+		return nil
+	}
+
+	if !shared {
+		if err := s.vectors.Delete(ctx, fact.VectorID); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.facts, id)
+	s.byText[fact.VectorID] = removeString(s.byText[fact.VectorID], id)
+	if len(s.byText[fact.VectorID]) == 0 {
+		delete(s.byText, fact.VectorID)
+	}
+	pair := pairKey(fact.SubjectID, fact.ObjectID)
+	s.byPair[pair] = removeString(s.byPair[pair], id)
+	if len(s.byPair[pair]) == 0 {
+		delete(s.byPair, pair)
+	}
+	return nil
+}
+
+// Size 返回事实数量
+func (s *FactStore) Size() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.facts)
+}
+
+// save 保存三元组到文件（向量由 vectors 自身保存）
+func (s *FactStore) save(path string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	jsonData, err := json.MarshalIndent(s.facts, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal data: %w", err)
+	}
+
+	if err := os.WriteFile(path, jsonData, 0644); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+
+	return nil
+}
+
+// load 从文件加载三元组并重建索引
+// 旧版本保存的事实没有 vector_id，其 ID 就是向量 ID
+func (s *FactStore) load(path string) error {
+	jsonData, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+
+	facts := make(map[string]*Fact)
+	if err := json.Unmarshal(jsonData, &facts); err != nil {
+		return fmt.Errorf("unmarshal data: %w", err)
+	}
+
+	ids := make([]string, 0, len(facts))
+	for id, fact := range facts {
+		fact.ID = id
+		if fact.VectorID == "" {
+			fact.VectorID = id
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	s.reset()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ids {
+		s.add(facts[id])
+	}
+	return nil
+}
+
+// reset 清空三元组和索引（向量由 vectors 自身管理）
+func (s *FactStore) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.facts = make(map[string]*Fact)
+	s.byText = make(map[string][]string)
+	s.byPair = make(map[[2]string][]string)
+}
+
+// removeString 返回去掉 s 之后的切片
+func removeString(values []string, s string) []string {
+	result := values[:0]
+	for _, v := range values {
+		if v != s {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// containsString 判断切片中是否包含 s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package hipporag
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/example/go-scaffold/pkg/embedding"
+)
+
+// TestFactStoreAmbiguousText 文本形式相同但字段不同的三元组是不同的事实，共用一个向量
+func TestFactStoreAmbiguousText(t *testing.T) {
+	ctx := context.Background()
+	vectors := embedding.NewStore(embedding.NewLocalClient(32))
+	s := NewFactStore(vectors)
+
+	ids, err := s.Insert(ctx, []Fact{
+		{Subject: "New York", Predicate: "is in", Object: "USA", SubjectID: "ny", ObjectID: "usa", ChunkIDs: []string{"c1"}},
+		{Subject: "New", Predicate: "York is in", Object: "USA", SubjectID: "new", ObjectID: "usa", ChunkIDs: []string{"c2"}},
+		{Subject: "New York", Predicate: "is in", Object: "USA", SubjectID: "ny", ObjectID: "usa", ChunkIDs: []string{"c3"}},
+	})
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if ids[0] == ids[1] || ids[0] != ids[2] {
+		t.Fatalf("ids = %v, want distinct ids for distinct triples and one id for the repeated triple", ids)
+	}
+	first, _ := s.Get(ids[0])
+	second, _ := s.Get(ids[1])
+	if first.VectorID != second.VectorID || vectors.Size() != 1 {
+		t.Errorf("vector ids %s, %s with %d vectors, want one shared vector", first.VectorID, second.VectorID, vectors.Size())
+	}
+	if len(first.ChunkIDs) != 2 {
+		t.Errorf("chunk ids = %v, want c1 and c3", first.ChunkIDs)
+	}
+
+	// 检索时两个事实都能返回，且不超过 topK
+	queryVec, _ := embedding.NewLocalClient(32).EmbedSingle(ctx, "New York is in USA")
+	facts, scores, err := s.Search(ctx, queryVec, 5)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(facts) != 2 || scores[0] != scores[1] {
+		t.Errorf("Search returned %d facts with scores %v, want 2 equal scores", len(facts), scores)
+	}
+	if facts, _, _ := s.Search(ctx, queryVec, 1); len(facts) != 1 {
+		t.Errorf("Search with topK 1 returned %d facts", len(facts))
+	}
+
+	// 删除其中一个事实时保留共用的向量，两个都删除后向量才删除
+	if err := s.Delete(ctx, ids[1]); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if vectors.Size() != 1 {
+		t.Errorf("shared vector deleted with the first fact")
+	}
+	if facts, _, _ := s.Search(ctx, queryVec, 5); len(facts) != 1 || facts[0].ID != ids[0] {
+		t.Errorf("Search after Delete: %+v", facts)
+	}
+	if err := s.Delete(ctx, ids[0]); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if vectors.Size() != 0 || s.Size() != 0 {
+		t.Errorf("after deleting both facts: %d vectors, %d facts", vectors.Size(), s.Size())
+	}
+}
+
+// TestFactStoreConnects 只有仍有来源文档块的事实才连接实体对（任一方向）
+func TestFactStoreConnects(t *testing.T) {
+	ctx := context.Background()
+	s := NewFactStore(embedding.NewStore(embedding.NewLocalClient(32)))
+	ids, err := s.Insert(ctx, []Fact{
+		{Subject: "A", Predicate: "knows", Object: "B", SubjectID: "a", ObjectID: "b", ChunkIDs: []string{"c1"}},
+		{Subject: "B", Predicate: "likes", Object: "A", SubjectID: "b", ObjectID: "a", ChunkIDs: []string{"c2"}},
+	})
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	if !s.connects("a", "b") || !s.connects("b", "a") || s.connects("a", "c") {
+		t.Error("connects does not match the inserted facts")
+	}
+	if !s.detachChunk(ids[0], "c1") {
+		t.Error("detachChunk: want true for the last chunk")
+	}
+	if !s.connects("a", "b") {
+		t.Error("pair lost while another fact still connects it")
+	}
+	s.detachChunk(ids[1], "c2")
+	if s.connects("a", "b") {
+		t.Error("pair still connected after every fact lost its chunks")
+	}
+}
+
+// TestFactStoreLoadLegacy 旧版本保存的事实没有 vector_id，以事实 ID 作为向量 ID
+func TestFactStoreLoadLegacy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), triplesFileName)
+	legacy := map[string]*Fact{
+		"0123456789abcdef": {ID: "0123456789abcdef", Subject: "A", Predicate: "knows", Object: "B", SubjectID: "a", ObjectID: "b", ChunkIDs: []string{"c1"}},
+	}
+	data, _ := json.Marshal(legacy)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewFactStore(embedding.NewStore(embedding.NewLocalClient(32)))
+	if err := s.load(path); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	fact, exists := s.Get("0123456789abcdef")
+	if !exists || fact.VectorID != "0123456789abcdef" {
+		t.Errorf("fact = %+v, %v", fact, exists)
+	}
+	if !s.connects("b", "a") {
+		t.Error("pair index not rebuilt on load")
+	}
+}
@@ -4,42 +4,112 @@ package hipporag
 // 用途：HippoRAG 系统的核心，整合知识图谱、向量检索和 LLM
 // 主要功能：
 // - Index: 索引文档（分块 → OpenIE → 构建图谱 → 向量化）
+// - AddDocuments: 向已有索引增量添加文档
+// - DeleteDocuments: 删除文档并回收不再被引用的文档块、事实和实体
 // - Retrieve: 检索相关文档（向量检索 + PPR 图检索）
 // - Query: 问答（检索 + LLM 生成）
 
 import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/example/go-scaffold/pkg/chunker"
 	"github.com/example/go-scaffold/pkg/embedding"
 	"github.com/example/go-scaffold/pkg/graph"
 	"github.com/example/go-scaffold/pkg/llm"
 	"github.com/example/go-scaffold/pkg/openie"
+	"github.com/example/go-scaffold/pkg/prompt"
+)
+
+// PPR 算法模式
+const (
+	PPRModeExact  = "exact"       // 幂迭代，计算全图分数
+	PPRModeApprox = "approximate" // 局部推送近似，只计算种子附近的节点
 )
 
+// defaultPPREpsilon 近似 PPR 的默认残差阈值
+const defaultPPREpsilon = 1e-4
+
 // Config HippoRAG 配置
 type Config struct {
 	// 文本分块参数
-	ChunkSize    int // 每块字符数，默认 512
-	ChunkOverlap int // 块重叠字符数，默认 50
+	ChunkSize    int // 每块字符数（token 策略下为 token 数），默认 512
+	ChunkOverlap int // 块重叠字符数（token 策略下为 token 数），默认 50
+	// Chunker 分块策略："rune"（默认，固定字符数）、"sentence"（句子边界）、"paragraph"（段落 / Markdown 标题）、
+	// "token"（按近似 BPE 分词器的 token 数；使用模型真实的分词器时用 SetChunker(chunker.NewTokenChunker(...))）
+	Chunker string
 
 	// PPR 参数
 	PPRDamping   float64 // 阻尼系数，默认 0.5
 	PPRMaxIter   int     // 最大迭代次数，默认 100
 	PPRTolerance float64 // 收敛阈值，默认 1e-6
 
+	// PPRWeighted 是否按归一化的出边权重传播分数，默认 false
+	// 关闭时每个节点的分数平均分配给所有邻居（与原有行为一致）；开启后 fact_back 等边的权重和 PPREdgeTypeWeights 才会生效
+	PPRWeighted bool
+	// PPREdgeTypeWeights 边类型权重乘数（仅 PPRWeighted 时生效）
+	// 例如 {"passage": 1.0, "fact": 1.0, "synonymy": 0.5}，未设置的类型乘数为 1.0
+	PPREdgeTypeWeights map[string]float64
+	// PPRDense 是否改用基于 map 的实现执行 PPR，默认 false
+	// 默认在图的 CSR 稀疏矩阵快照上执行，大图上比基于 map 的实现快得多，快照在图变更后惰性重建
+	PPRDense bool
+	// PPRMode PPR 算法："exact"（默认）或 "approximate"
+	PPRMode string
+	// PPREpsilon 近似 PPR 的残差阈值，默认 1e-4，<= 0 时使用默认值（仅 approximate 模式生效）
+	PPREpsilon float64
+
+	// OpenIE 参数
+	OpenIEConcurrency int                // 并发调用 LLM 的数量，默认 4
+	OpenIEErrorPolicy openie.ErrorPolicy // 文本块抽取失败时的策略，默认 fail_fast
+	OpenIEMaxRetries  int                // retry 策略下每个文本块的最大重试次数，默认 2
+	OpenIEMaxRepairs  int                // 输出修复后仍无法解析时带着错误重新请求 LLM 的最大次数，默认 2
+	// OpenIEMode 抽取模式：single 一次性抽取（默认），two_stage 先 NER 再抽取三元组（HippoRAG 论文的做法）
+	OpenIEMode openie.ExtractionMode
+	// OpenIECacheDir 抽取结果缓存目录，为空时不缓存
+	// 缓存按模型名和提示词区分版本，重新索引时只对新的或修改过的文档块调用 LLM
+	OpenIECacheDir string
+
+	// 同义边参数
+	// SynonymyThreshold 实体向量相似度不低于该值时添加 synonymy 边，默认 0.8
+	SynonymyThreshold float64
+	// SynonymyMaxNeighbors 每个实体最多连接的同义邻居数，默认 10；<= 0 时不构建同义边
+	SynonymyMaxNeighbors int
+
 	// 检索参数
 	TopKEntities int // 检索的实体数量，默认 10
 	TopKChunks   int // 最终返回的文档块数量，默认 5
+
+	// 提示词参数
+	// PromptLanguage 提示词语言（OpenIE、重排序、问答）："default"（默认，与原有提示词相同：OpenIE 英文，重排序和问答中文）、"zh" 或 "en"
+	PromptLanguage string
+	// PromptDir 覆盖模板目录（<dir>/<语言>/<名称>.tmpl），为空时只使用内置模板；没有该语言的子目录时保持内置模板
+	PromptDir string
 }
 
 // DefaultConfig 返回默认配置
 func DefaultConfig() *Config {
 	return &Config{
-		ChunkSize:    512,
-		ChunkOverlap: 50,
-		PPRDamping:   0.5,
-		PPRMaxIter:   100,
-		PPRTolerance: 1e-6,
-		TopKEntities: 10,
-		TopKChunks:   5,
+		ChunkSize:            512,
+		ChunkOverlap:         50,
+		Chunker:              chunker.StrategyRune,
+		PPRDamping:           0.5,
+		PPRMaxIter:           100,
+		PPRTolerance:         1e-6,
+		PPRWeighted:          false,
+		PPRDense:             false,
+		PPRMode:              PPRModeExact,
+		PPREpsilon:           1e-4,
+		OpenIEConcurrency:    4,
+		OpenIEErrorPolicy:    openie.ErrorPolicyFailFast,
+		OpenIEMaxRetries:     2,
+		OpenIEMaxRepairs:     2,
+		OpenIEMode:           openie.ModeSingle,
+		SynonymyThreshold:    0.8,
+		SynonymyMaxNeighbors: 10,
+		TopKEntities:         10,
+		TopKChunks:           5,
+		PromptLanguage:       prompt.DefaultLanguage,
 	}
 }
 
@@ -49,36 +119,49 @@ type HippoRAG struct {
 	config *Config
 
 	// 客户端
-	llmClient       *llm.OpenAIClient
+	llmClient       llm.Client
 	embeddingClient embedding.Client
 
 	// 存储（使用接口，支持内存或 Weaviate）
 	chunkStore  embedding.VectorStore // 文档块向量存储
 	entityStore embedding.VectorStore // 实体向量存储
-	factStore   embedding.VectorStore // 事实向量存储
+
+	// 事实存储（结构化三元组 + 事实文本向量）
+	facts *FactStore
 
 	// 知识图谱
 	graph *graph.Graph
 
-	// OpenIE 抽取器
-	openie *openie.Extractor
+	// 提示词模板
+	prompts *prompt.Registry
+
+	// 文档分块器（按 Config.Chunker 创建，可用 SetChunker 替换）
+	chunker chunker.Chunker
+
+	// OpenIE 抽取器（默认基于 LLM，可用 SetExtractor 替换）
+	openie openie.TextExtractor
+
+	// 来源关系（文档 -> 文档块 -> 实体/事实），用于删除文档
+	catalog *catalog
 
 	// 缓存
 	queryEmbeddings map[string][]float64
 
 	// 状态
-	readyToRetrieve bool
+	readyToRetrieve atomic.Bool
+	indexMu         sync.Mutex // 串行化索引更新，检索不受影响
 }
 
 // NewHippoRAG 创建 HippoRAG 实例（使用内存存储）
 func NewHippoRAG(
 	config *Config,
 	embeddingClient embedding.Client,
-	llmClient *llm.OpenAIClient,
+	llmClient llm.Client,
 ) *HippoRAG {
 	if config == nil {
 		config = DefaultConfig()
 	}
+	prompts := newPrompts(config)
 
 	return &HippoRAG{
 		config:          config,
@@ -86,11 +169,13 @@ func NewHippoRAG(
 		llmClient:       llmClient,
 		chunkStore:      embedding.NewStore(embeddingClient),
 		entityStore:     embedding.NewStore(embeddingClient),
-		factStore:       embedding.NewStore(embeddingClient),
+		facts:           NewFactStore(embedding.NewStore(embeddingClient)),
 		graph:           graph.NewGraph(),
-		openie:          openie.NewExtractor(llmClient),
+		prompts:         prompts,
+		chunker:         newChunker(config),
+		openie:          newExtractor(config, llmClient, prompts),
+		catalog:         newCatalog(),
 		queryEmbeddings: make(map[string][]float64),
-		readyToRetrieve: false,
 	}
 }
 
@@ -98,7 +183,7 @@ func NewHippoRAG(
 func NewHippoRAGWithStores(
 	config *Config,
 	embeddingClient embedding.Client,
-	llmClient *llm.OpenAIClient,
+	llmClient llm.Client,
 	chunkStore embedding.VectorStore,
 	entityStore embedding.VectorStore,
 	factStore embedding.VectorStore,
@@ -106,6 +191,7 @@ func NewHippoRAGWithStores(
 	if config == nil {
 		config = DefaultConfig()
 	}
+	prompts := newPrompts(config)
 
 	return &HippoRAG{
 		config:          config,
@@ -113,18 +199,145 @@ func NewHippoRAGWithStores(
 		llmClient:       llmClient,
 		chunkStore:      chunkStore,
 		entityStore:     entityStore,
-		factStore:       factStore,
+		facts:           NewFactStore(factStore),
 		graph:           graph.NewGraph(),
-		openie:          openie.NewExtractor(llmClient),
+		prompts:         prompts,
+		chunker:         newChunker(config),
+		openie:          newExtractor(config, llmClient, prompts),
+		catalog:         newCatalog(),
 		queryEmbeddings: make(map[string][]float64),
-		readyToRetrieve: false,
 	}
 }
 
+// newChunker 按配置创建分块器，未知策略时打印警告并使用 rune 分块
+func newChunker(config *Config) chunker.Chunker {
+	c, err := chunker.New(config.Chunker, config.ChunkSize, config.ChunkOverlap)
+	if err != nil {
+		fmt.Printf("Warning: %v, using %s chunker\n", err, chunker.StrategyRune)
+		return chunker.NewRuneChunker(config.ChunkSize, config.ChunkOverlap)
+	}
+	return c
+}
+
+// LoadPrompts 按 Config.PromptLanguage / PromptDir 加载提示词模板
+// 语言未知或覆盖目录无法加载（如模板解析失败）时返回错误；需要在出错时终止的调用方可在 NewHippoRAG 之前调用
+func LoadPrompts(config *Config) (*prompt.Registry, error) {
+	prompts, err := prompt.NewRegistry(config.PromptLanguage)
+	if err != nil {
+		return nil, err
+	}
+	if config.PromptDir == "" {
+		return prompts, nil
+	}
+
+	if err := prompts.LoadDir(config.PromptDir); err != nil {
+		return nil, err
+	}
+	return prompts, nil
+}
+
+// newPrompts 按配置加载提示词模板
+// 语言未知时打印警告并使用默认语言的内置模板；覆盖目录无法加载时打印警告并使用该语言的内置模板
+func newPrompts(config *Config) *prompt.Registry {
+	prompts, err := LoadPrompts(config)
+	if err == nil {
+		return prompts
+	}
+
+	// LoadDir 失败前可能已覆盖了部分模板，重新加载内置模板
+	builtin, langErr := prompt.NewRegistry(config.PromptLanguage)
+	if langErr != nil {
+		fmt.Printf("Warning: load prompts: %v, using built-in %s prompts\n", err, prompt.DefaultLanguage)
+		return prompt.Default()
+	}
+	fmt.Printf("Warning: load prompts: %v, using built-in %s prompts\n", err, builtin.Language())
+	return builtin
+}
+
+// newExtractor 按配置创建 OpenIE 抽取器
+func newExtractor(config *Config, llmClient llm.Client, prompts *prompt.Registry) *openie.Extractor {
+	extractor := openie.NewExtractor(llmClient)
+	extractor.SetPrompts(prompts)
+	extractor.SetConcurrency(config.OpenIEConcurrency)
+	// 未知策略时保持默认的 fail_fast
+	_ = extractor.SetErrorPolicy(config.OpenIEErrorPolicy, config.OpenIEMaxRetries)
+	extractor.SetMaxRepairs(config.OpenIEMaxRepairs)
+	// 未知模式时保持默认的 single
+	_ = extractor.SetMode(config.OpenIEMode)
+	if config.OpenIECacheDir != "" {
+		extractor.SetCache(openie.NewCache(config.OpenIECacheDir, extractor.CacheVersion()))
+	}
+	return extractor
+}
+
+// SetExtractor 替换 OpenIE 抽取器
+// 例如 openie.NewRuleExtractor()（无需 LLM），或 openie.NewFallbackExtractor(LLM 抽取器, 规则抽取器)
+// 只影响之后索引的文档块
+func (h *HippoRAG) SetExtractor(extractor openie.TextExtractor) {
+	h.indexMu.Lock()
+	defer h.indexMu.Unlock()
+
+	h.openie = extractor
+}
+
+// SetPrompts 替换提示词模板（重排序、问答，以及按配置创建的 LLM 抽取器）
+// 抽取器设置了缓存时，缓存版本随新模板更新；SetExtractor 设置的其他类型抽取器不受影响
+// 检索不加锁读取模板，应在开始检索之前调用
+func (h *HippoRAG) SetPrompts(prompts *prompt.Registry) {
+	h.indexMu.Lock()
+	defer h.indexMu.Unlock()
+
+	h.prompts = prompts
+	if extractor, ok := h.openie.(*openie.Extractor); ok {
+		extractor.SetPrompts(prompts)
+		if h.config.OpenIECacheDir != "" {
+			extractor.SetCache(openie.NewCache(h.config.OpenIECacheDir, extractor.CacheVersion()))
+		}
+	}
+}
+
+// PromptVersions 返回使用的提示词模板版本 ID，可随评测结果一起保存
+func (h *HippoRAG) PromptVersions() []string {
+	return h.prompts.Versions()
+}
+
+// SetChunker 替换文档分块器，只影响之后索引的文档
+func (h *HippoRAG) SetChunker(c chunker.Chunker) {
+	h.indexMu.Lock()
+	defer h.indexMu.Unlock()
+
+	h.chunker = c
+}
+
 // QuerySolution 查询解决方案（检索结果）
 type QuerySolution struct {
-	Query      string    // 查询文本
-	ChunkIDs   []string  // 相关文档块 ID
-	ChunkTexts []string  // 相关文档块内容
-	Scores     []float64 // 相关性分数
+	Query      string           // 查询文本
+	ChunkIDs   []string         // 相关文档块 ID
+	ChunkTexts []string         // 相关文档块内容
+	Scores     []float64        // 相关性分数
+	Chunks     []RetrievedChunk // 相关文档块及其来源（与 ChunkIDs 一一对应）
+}
+
+// RetrievedChunk 检索到的文档块及其来源文档
+// 同一文档块出现在多个文档中时，来源为最早索引的文档
+type RetrievedChunk struct {
+	ID       string            // 文档块 ID
+	Text     string            // 文档块内容（已清理空白）
+	Score    float64           // 相关性分数
+	DocID    string            // 来源文档 ID
+	Start    int               // 在来源文档原文中的起始字节偏移
+	End      int               // 在来源文档原文中的结束字节偏移（不含）
+	Metadata map[string]string // 来源文档的元数据（标题、URL 等）
+}
+
+// newRetrievedChunk 构造带来源信息的检索结果
+func (h *HippoRAG) newRetrievedChunk(id, text string, score float64) RetrievedChunk {
+	chunk := RetrievedChunk{ID: id, Text: text, Score: score}
+	if source, metadata, ok := h.catalog.source(id); ok {
+		chunk.DocID = source.DocID
+		chunk.Start = source.Start
+		chunk.End = source.End
+		chunk.Metadata = metadata
+	}
+	return chunk
 }
@@ -0,0 +1,176 @@
+package hipporag
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/example/go-scaffold/pkg/document"
+	"github.com/example/go-scaffold/pkg/prompt"
+)
+
+// offlineDocs 离线测试语料（规则抽取器能从中抽取出实体和事实）
+var offlineDocs = []document.Document{
+	{ID: "einstein", Text: "爱因斯坦提出了相对论。爱因斯坦出生于乌尔姆。", Metadata: map[string]string{document.MetaTitle: "爱因斯坦"}},
+	{ID: "curie", Text: "居里夫人发现了镭。居里夫人获得了诺贝尔奖。", Metadata: map[string]string{document.MetaTitle: "居里夫人"}},
+	{ID: "newton", Text: "牛顿提出了万有引力定律。牛顿毕业于剑桥大学。", Metadata: map[string]string{document.MetaTitle: "牛顿"}},
+}
+
+// topDocument 检索 query，返回排名第一的文档块
+func topDocument(t *testing.T, h *HippoRAG, query string) RetrievedChunk {
+	t.Helper()
+	solutions, err := h.Retrieve(context.Background(), []string{query}, 3)
+	if err != nil {
+		t.Fatalf("Retrieve(%q): %v", query, err)
+	}
+	if len(solutions) != 1 || len(solutions[0].Chunks) == 0 {
+		t.Fatalf("Retrieve(%q): no results", query)
+	}
+	return solutions[0].Chunks[0]
+}
+
+// TestOfflineLifecycle 不依赖网络完成 Insert → Retrieve → DeleteDocuments → Save / Load
+func TestOfflineLifecycle(t *testing.T) {
+	ctx := context.Background()
+	h := newOfflineHippoRAG()
+
+	if _, err := h.Retrieve(ctx, []string{"相对论"}, 3); err == nil {
+		t.Error("Retrieve before indexing: want error")
+	}
+
+	if err := h.Insert(ctx, offlineDocs); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	assertConsistent(t, h)
+	if stats := h.Stats(ctx); stats["chunks"] != 3 || stats["facts"] != 6 {
+		t.Errorf("Stats: got %v, want 3 chunks and 6 facts", stats)
+	}
+
+	// 检索结果带有来源文档和元数据
+	top := topDocument(t, h, "居里夫人发现了什么？")
+	if top.DocID != "curie" || top.Metadata[document.MetaTitle] != "居里夫人" {
+		t.Errorf("top chunk: doc %q, metadata %v, want curie", top.DocID, top.Metadata)
+	}
+	if got := offlineDocs[1].Text[top.Start:top.End]; got != top.Text {
+		t.Errorf("chunk span %d-%d: got %q, want %q", top.Start, top.End, got, top.Text)
+	}
+
+	// 重复插入被跳过
+	if err := h.Insert(ctx, offlineDocs[:1]); err != nil {
+		t.Fatalf("Insert again: %v", err)
+	}
+	if stats := h.Stats(ctx); stats["chunks"] != 3 {
+		t.Errorf("chunks after re-insert: got %d, want 3", stats["chunks"])
+	}
+
+	// 删除后不再检索到该文档
+	if err := h.DeleteDocuments(ctx, []string{"curie"}); err != nil {
+		t.Fatalf("DeleteDocuments: %v", err)
+	}
+	assertConsistent(t, h)
+	solutions, err := h.Retrieve(ctx, []string{"居里夫人发现了什么？"}, 3)
+	if err != nil {
+		t.Fatalf("Retrieve after delete: %v", err)
+	}
+	for _, chunk := range solutions[0].Chunks {
+		if chunk.DocID == "curie" {
+			t.Errorf("deleted document is still retrieved: %+v", chunk)
+		}
+	}
+
+	// 保存后加载到新实例，检索结果和统计一致
+	dir := t.TempDir()
+	if err := h.Save(dir); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded := newOfflineHippoRAG()
+	if err := loaded.Load(dir); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	assertConsistent(t, loaded)
+
+	if !reflect.DeepEqual(loaded.Stats(ctx), h.Stats(ctx)) {
+		t.Errorf("Stats after Load: got %v, want %v", loaded.Stats(ctx), h.Stats(ctx))
+	}
+	if !reflect.DeepEqual(loaded.Documents(), h.Documents()) {
+		t.Errorf("Documents after Load: got %v, want %v", loaded.Documents(), h.Documents())
+	}
+	for _, query := range []string{"谁提出了相对论？", "牛顿毕业于哪里？"} {
+		want, got := topDocument(t, h, query), topDocument(t, loaded, query)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Retrieve(%q) after Load: got %+v, want %+v", query, got, want)
+		}
+	}
+	if top := topDocument(t, loaded, "牛顿毕业于哪里？"); top.DocID != "newton" {
+		t.Errorf("top chunk after Load: doc %q, want newton", top.DocID)
+	}
+
+	// 加载的索引可以继续删除文档
+	if err := loaded.DeleteDocuments(ctx, []string{"newton"}); err != nil {
+		t.Fatalf("DeleteDocuments after Load: %v", err)
+	}
+	assertConsistent(t, loaded)
+	if docs := loaded.Documents(); !reflect.DeepEqual(docs, []string{"einstein"}) {
+		t.Errorf("Documents: got %v, want [einstein]", docs)
+	}
+}
+
+// TestNewPromptsKeepsLanguage 覆盖目录中没有所选语言的子目录时，保持该语言的内置模板而不是回退到中文
+func TestNewPromptsKeepsLanguage(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, prompt.LanguageChinese), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	config := DefaultConfig()
+	config.PromptLanguage = prompt.LanguageEnglish
+	config.PromptDir = dir
+	want, _ := prompt.NewRegistry(prompt.LanguageEnglish)
+
+	if got := newPrompts(config); !reflect.DeepEqual(got.Versions(), want.Versions()) {
+		t.Errorf("prompts: got %v, want %v", got.Versions(), want.Versions())
+	}
+
+	// 覆盖模板解析失败时同样保持所选语言
+	if err := os.MkdirAll(filepath.Join(dir, prompt.LanguageEnglish), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, prompt.LanguageEnglish, "qa.tmpl"), []byte("{{.Question"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := newPrompts(config); !reflect.DeepEqual(got.Versions(), want.Versions()) {
+		t.Errorf("prompts after a parse error: got %v, want %v", got.Versions(), want.Versions())
+	}
+}
+
+// TestLoadPrompts 默认配置使用默认模板集；语言未知或覆盖模板解析失败时返回错误
+func TestLoadPrompts(t *testing.T) {
+	config := DefaultConfig()
+	prompts, err := LoadPrompts(config)
+	if err != nil {
+		t.Fatalf("LoadPrompts: %v", err)
+	}
+	if !reflect.DeepEqual(prompts.Versions(), prompt.Default().Versions()) {
+		t.Errorf("default config: got %v, want the default prompts", prompts.Versions())
+	}
+
+	config.PromptLanguage = "fr"
+	if _, err := LoadPrompts(config); err == nil {
+		t.Error("unknown language: want error")
+	}
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, prompt.LanguageChinese), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, prompt.LanguageChinese, "qa.tmpl"), []byte("{{.Question"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config.PromptLanguage = ""
+	config.PromptDir = dir
+	if _, err := LoadPrompts(config); err == nil {
+		t.Error("parse error in the override dir: want error")
+	}
+}
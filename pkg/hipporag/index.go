@@ -7,68 +7,161 @@ package hipporag
 // 2. OpenIE 提取实体和关系
 // 3. 构建知识图谱（节点：实体+文档块，边：关系）
 // 4. 向量化存储（文档块、实体、事实）
+// 支持增量索引：只处理新的文档块，新增部分原子地合并进图谱
 
 import (
 	"context"
 	"fmt"
 
+	"github.com/example/go-scaffold/pkg/document"
 	"github.com/example/go-scaffold/pkg/embedding"
+	"github.com/example/go-scaffold/pkg/graph"
+	"github.com/example/go-scaffold/pkg/openie"
 	"github.com/example/go-scaffold/pkg/utils"
 )
 
 // Index 索引文档列表
 // docs: 文档文本数组
-// 返回：索引的文档块数量
+// 可以多次调用，已索引的内容会被跳过（等价于 AddDocuments）
 func (h *HippoRAG) Index(ctx context.Context, docs []string) error {
 	if len(docs) == 0 {
 		return fmt.Errorf("no documents to index")
 	}
 
+	return h.AddDocuments(ctx, docs)
+}
+
+// AddDocuments 向已有索引增量添加文档（文档 ID 按内容生成，见 DocumentID）
+func (h *HippoRAG) AddDocuments(ctx context.Context, docs []string) error {
+	return h.Insert(ctx, document.FromTexts(docs))
+}
+
+// Insert 向已有索引增量添加文档
+// 文档 ID 为空时按内容生成；ID 已存在的文档会被跳过（如需更新内容，先调用 DeleteDocuments）
+// 只对新的文档块执行 OpenIE 和向量化；已存在的实体按内容哈希复用同一个节点
+// 新的节点和边在一次操作中合并进图谱，更新期间检索可以正常进行
+func (h *HippoRAG) Insert(ctx context.Context, docs []document.Document) error {
+	h.indexMu.Lock()
+	defer h.indexMu.Unlock()
+
+	// 跳过已索引的文档
+	var pending []document.Document
+	pendingIDs := make(map[string]bool)
+	for _, doc := range docs {
+		if doc.ID == "" {
+			doc.ID = DocumentID(doc.Text)
+		}
+		if pendingIDs[doc.ID] || h.catalog.hasDocument(doc.ID) {
+			continue
+		}
+		pendingIDs[doc.ID] = true
+		pending = append(pending, doc)
+	}
+	if skipped := len(docs) - len(pending); skipped > 0 {
+		fmt.Printf("Skipped %d already indexed documents\n", skipped)
+	}
+	docs = pending
+
 	// 步骤 1: 文档分块
 	fmt.Println("Step 1: Chunking documents...")
 	var allChunks []string
+	var allSpans []utils.TextChunk
 	var chunkToDoc []int // 记录每个块属于哪个文档
 
 	for docIdx, doc := range docs {
-		chunks := utils.ChunkText(doc, h.config.ChunkSize, h.config.ChunkOverlap)
-		for _, chunk := range chunks {
-			allChunks = append(allChunks, chunk)
+		spans := h.chunker.Chunk(doc.Text)
+		for _, span := range spans {
+			allChunks = append(allChunks, span.Text)
+			allSpans = append(allSpans, span)
 			chunkToDoc = append(chunkToDoc, docIdx)
 		}
 	}
 	fmt.Printf("  Created %d chunks from %d documents\n", len(allChunks), len(docs))
 
-	// 步骤 2: 向量化文档块
+	// 步骤 2: 向量化文档块（存储按内容哈希去重，已有的块不会重新向量化）
 	fmt.Println("Step 2: Embedding chunks...")
-	chunkIDs, err := h.chunkStore.Insert(ctx, allChunks)
+	allChunkIDs, err := h.chunkStore.Insert(ctx, allChunks)
 	if err != nil {
 		return fmt.Errorf("insert chunks: %w", err)
 	}
-	fmt.Printf("  Embedded %d chunks\n", len(chunkIDs))
 
-	// 步骤 3: OpenIE 提取实体和关系
+	// 筛选出图谱中还没有的文档块
+	var chunks, chunkIDs []string
+	seen := make(map[string]bool)
+	for i, chunkID := range allChunkIDs {
+		if seen[chunkID] {
+			continue
+		}
+		seen[chunkID] = true
+		if _, exists := h.graph.GetNode(chunkID); exists {
+			continue
+		}
+		chunks = append(chunks, allChunks[i])
+		chunkIDs = append(chunkIDs, chunkID)
+	}
+	fmt.Printf("  Embedded %d chunks (%d new)\n", len(allChunkIDs), len(chunkIDs))
+
+	// 登记文档包含的文档块（在图谱更新成功后执行）
+	registerDocuments := func() {
+		docChunkIDs := make([][]string, len(docs))
+		docSpans := make([][]utils.TextChunk, len(docs))
+		for i, chunkID := range allChunkIDs {
+			docChunkIDs[chunkToDoc[i]] = append(docChunkIDs[chunkToDoc[i]], chunkID)
+			docSpans[chunkToDoc[i]] = append(docSpans[chunkToDoc[i]], allSpans[i])
+		}
+		for docIdx, doc := range docs {
+			h.catalog.addDocument(doc.ID, doc.Metadata, docChunkIDs[docIdx], docSpans[docIdx])
+		}
+	}
+
+	if len(chunkIDs) == 0 {
+		registerDocuments()
+		h.readyToRetrieve.Store(true)
+		fmt.Println("No new content, index unchanged.")
+		return nil
+	}
+
+	// 步骤 3: OpenIE 提取实体和关系（仅新文档块）
 	fmt.Println("Step 3: Extracting entities and relations...")
-	extractions, err := h.openie.ExtractBatch(ctx, allChunks)
+	validator, validates := h.openie.(interface{ ValidationStats() openie.ValidationStats })
+	var statsBefore openie.ValidationStats
+	if validates {
+		statsBefore = validator.ValidationStats()
+	}
+	batch, err := h.openie.ExtractBatch(ctx, chunks)
 	if err != nil {
 		return fmt.Errorf("extract entities: %w", err)
 	}
+	if validates {
+		// 两阶段抽取时报告本次的三元组校验结果
+		stats := validator.ValidationStats()
+		if total := stats.Total - statsBefore.Total; total > 0 {
+			fmt.Printf("  Validated %d triples: %d kept, %d repaired, %d dropped\n", total,
+				stats.Kept-statsBefore.Kept, stats.Repaired-statsBefore.Repaired, stats.Dropped-statsBefore.Dropped)
+		}
+	}
+
+	// 抽取失败的文档块仍作为节点加入图谱（可被段落检索命中），只是没有实体和事实
+	extractions := batch.Results
+	for _, failed := range batch.Failed {
+		fmt.Printf("  Warning: extraction failed for chunk %s after %d attempts: %v\n",
+			chunkIDs[failed.Index], failed.Attempts, failed.Err)
+		extractions[failed.Index] = &openie.ExtractionResult{}
+	}
 
-	// 收集所有唯一实体和事实
+	// 收集所有唯一实体和事实数量
 	entitySet := make(map[string]bool)
-	var allFacts []string
+	factCount := 0
 
 	for _, extraction := range extractions {
 		for _, entity := range extraction.Entities {
 			entitySet[entity] = true
 		}
 		for _, triple := range extraction.Triples {
-			// 将三元组转换为文本形式
-			fact := fmt.Sprintf("%s %s %s", triple.Subject, triple.Predicate, triple.Object)
-			allFacts = append(allFacts, fact)
-
 			// 也将主语和宾语加入实体集合
 			entitySet[triple.Subject] = true
 			entitySet[triple.Object] = true
+			factCount++
 		}
 	}
 
@@ -76,7 +169,7 @@ func (h *HippoRAG) Index(ctx context.Context, docs []string) error {
 	for entity := range entitySet {
 		entities = append(entities, entity)
 	}
-	fmt.Printf("  Extracted %d unique entities and %d facts\n", len(entities), len(allFacts))
+	fmt.Printf("  Extracted %d unique entities and %d facts\n", len(entities), factCount)
 
 	// 步骤 4: 向量化实体和事实
 	fmt.Println("Step 4: Embedding entities and facts...")
@@ -85,33 +178,56 @@ func (h *HippoRAG) Index(ctx context.Context, docs []string) error {
 		return fmt.Errorf("insert entities: %w", err)
 	}
 
-	var factIDs []string
-	if len(allFacts) > 0 {
-		factIDs, err = h.factStore.Insert(ctx, allFacts)
-		if err != nil {
-			return fmt.Errorf("insert facts: %w", err)
+	entityIDMap := make(map[string]string) // entity text -> ID
+	for i, entity := range entities {
+		entityIDMap[entity] = entityIDs[i]
+	}
+
+	// 事实保留三元组结构，并关联主语/宾语实体节点和来源文档块
+	allFacts := make([]Fact, 0, factCount)
+	for chunkIdx, extraction := range extractions {
+		for _, triple := range extraction.Triples {
+			allFacts = append(allFacts, Fact{
+				Subject:   triple.Subject,
+				Predicate: triple.Predicate,
+				Object:    triple.Object,
+				SubjectID: entityIDMap[triple.Subject],
+				ObjectID:  entityIDMap[triple.Object],
+				ChunkIDs:  []string{chunkIDs[chunkIdx]},
+			})
 		}
 	}
+
+	factIDs, err := h.facts.Insert(ctx, allFacts)
+	if err != nil {
+		return fmt.Errorf("insert facts: %w", err)
+	}
 	fmt.Printf("  Embedded %d entities and %d facts\n", len(entityIDs), len(factIDs))
 
 	// 步骤 5: 构建知识图谱
+	// 先在子图中构建新增部分，再一次性合并，避免检索看到不完整的图
 	fmt.Println("Step 5: Building knowledge graph...")
+	sub := graph.NewGraph()
 
 	// 5.1 添加文档块节点
-	for _, chunkID := range chunkIDs {
-		content, _ := h.chunkStore.GetContent(ctx, chunkID)
-		h.graph.AddNode(chunkID, content, "chunk")
+	for i, chunkID := range chunkIDs {
+		sub.AddNode(chunkID, chunks[i], "chunk")
 	}
 
-	// 5.2 添加实体节点
-	entityIDMap := make(map[string]string) // entity text -> ID
+	// 5.2 添加实体节点（已存在的实体复用同一 ID）
+	var newEntityIDs []string
 	for i, entity := range entities {
 		entityID := entityIDs[i]
-		h.graph.AddNode(entityID, entity, "entity")
-		entityIDMap[entity] = entityID
+		if _, exists := h.graph.GetNode(entityID); !exists {
+			newEntityIDs = append(newEntityIDs, entityID)
+		}
+		sub.AddNode(entityID, entity, "entity")
 	}
+	fmt.Printf("  %d new entities, %d reused\n", len(newEntityIDs), len(entities)-len(newEntityIDs))
 
 	// 5.3 添加边
+	var factPairs [][2]string
+	seenPairs := make(map[[2]string]bool)
 	for chunkIdx, extraction := range extractions {
 		chunkID := chunkIDs[chunkIdx]
 
@@ -119,38 +235,92 @@ func (h *HippoRAG) Index(ctx context.Context, docs []string) error {
 		for _, entity := range extraction.Entities {
 			if entityID, exists := entityIDMap[entity]; exists {
 				// 正向：chunk -> entity
-				h.graph.AddEdge(chunkID, entityID, 1.0, "passage")
+				sub.AddEdge(chunkID, entityID, 1.0, "passage")
 				// 反向：entity -> chunk（让 PPR 能传播回文档块）
-				h.graph.AddEdge(entityID, chunkID, 1.0, "passage_back")
+				sub.AddEdge(entityID, chunkID, 1.0, "passage_back")
 			}
 		}
 
-		// 添加 fact 边：实体 <-> 实体（双向，支持双向推理）
+		// 记录三元组连接的实体对
 		for _, triple := range extraction.Triples {
 			subjectID, subjectExists := entityIDMap[triple.Subject]
 			objectID, objectExists := entityIDMap[triple.Object]
 
-			if subjectExists && objectExists {
-				// 正向边
-				h.graph.AddEdge(subjectID, objectID, 1.0, "fact")
-				// 反向边（权重可以稍低）
-				h.graph.AddEdge(objectID, subjectID, 0.5, "fact_back")
+			if pair := pairKey(subjectID, objectID); subjectExists && objectExists && !seenPairs[pair] {
+				seenPairs[pair] = true
+				factPairs = append(factPairs, pair)
 			}
 		}
 	}
 
+	// 添加 fact 边：实体 <-> 实体（双向，支持双向推理）
+	// 边由连接该实体对的全部事实（包括之前索引的事实）决定，与三元组的顺序无关
+	for _, pair := range factPairs {
+		h.linkFactPair(sub, pair[0], pair[1])
+	}
+
+	// 5.4 添加 synonymy 边：新增实体 <-> 语义相近的实体（双向）
+	synonyms, err := h.addSynonymyEdges(ctx, sub, newEntityIDs)
+	if err != nil {
+		return fmt.Errorf("add synonymy edges: %w", err)
+	}
+	fmt.Printf("  Linked %d synonym pairs\n", synonyms)
+
+	h.graph.Merge(sub)
+
+	// 5.5 登记来源关系：文档块 -> 实体 / 事实
+	factIdx := 0
+	for chunkIdx, extraction := range extractions {
+		var chunkEntityIDs []string
+		chunkEntitySet := make(map[string]bool)
+		addEntity := func(entity string) {
+			if entityID, exists := entityIDMap[entity]; exists && !chunkEntitySet[entityID] {
+				chunkEntitySet[entityID] = true
+				chunkEntityIDs = append(chunkEntityIDs, entityID)
+			}
+		}
+
+		var chunkFactIDs []string
+		for _, entity := range extraction.Entities {
+			addEntity(entity)
+		}
+		for _, triple := range extraction.Triples {
+			addEntity(triple.Subject)
+			addEntity(triple.Object)
+			chunkFactIDs = append(chunkFactIDs, factIDs[factIdx])
+			factIdx++
+		}
+
+		h.catalog.addChunk(chunkIDs[chunkIdx], chunkEntityIDs, chunkFactIDs)
+	}
+	registerDocuments()
 	fmt.Printf("  Graph: %d nodes, %d edges\n", h.graph.NodeCount(), h.graph.EdgeCount())
 
 	// 标记为可检索
-	h.readyToRetrieve = true
+	h.readyToRetrieve.Store(true)
 
 	fmt.Println("Indexing completed successfully!")
 	return nil
 }
 
+// linkFactPair 按事实存储重新设置实体对 a、b 之间的 fact 边
+// 每个方向上：有以起点为主语的事实时为 fact 边（权重 1.0），只有反方向的事实时为 fact_back 边（权重 0.5）
+func (h *HippoRAG) linkFactPair(g *graph.Graph, a, b string) {
+	for _, direction := range [][2]string{{a, b}, {b, a}} {
+		from, to := direction[0], direction[1]
+		switch {
+		case h.facts.links(from, to):
+			g.AddEdge(from, to, 1.0, "fact")
+		case h.facts.links(to, from):
+			// 反向边（权重可以稍低）
+			g.AddEdge(from, to, 0.5, "fact_back")
+		}
+	}
+}
+
 // IsReady 检查是否已完成索引，可以进行检索
 func (h *HippoRAG) IsReady() bool {
-	return h.readyToRetrieve
+	return h.readyToRetrieve.Load()
 }
 
 // Stats 返回索引统计信息
@@ -160,7 +330,7 @@ func (h *HippoRAG) Stats(ctx context.Context) map[string]int {
 	return map[string]int{
 		"chunks":   h.chunkStore.(*embedding.Store).Size(),
 		"entities": h.entityStore.(*embedding.Store).Size(),
-		"facts":    h.factStore.(*embedding.Store).Size(),
+		"facts":    h.facts.Size(),
 		"nodes":    h.graph.NodeCount(),
 		"edges":    h.graph.EdgeCount(),
 	}
@@ -0,0 +1,74 @@
+package hipporag
+
+import (
+	"context"
+	"testing"
+
+	"github.com/example/go-scaffold/pkg/document"
+	"github.com/example/go-scaffold/pkg/openie"
+)
+
+// 同一实体对上两个方向各有一个事实的文档
+var mentorDocs = []document.Document{
+	{ID: "student", Text: "玻尔师从卢瑟福。"},
+	{ID: "mentor", Text: "卢瑟福指导玻尔。"},
+}
+
+// newMentorHippoRAG 创建能从 mentorDocs 抽取事实的离线实例
+func newMentorHippoRAG(t *testing.T) *HippoRAG {
+	t.Helper()
+	extractor := openie.NewRuleExtractor()
+	if err := extractor.AddRule("师从", `^(?P<subject>.+?)师从(?P<object>.+?)。?$`); err != nil {
+		t.Fatal(err)
+	}
+	if err := extractor.AddRule("指导", `^(?P<subject>.+?)指导(?P<object>.+?)。?$`); err != nil {
+		t.Fatal(err)
+	}
+	h := newOfflineHippoRAG()
+	h.SetExtractor(extractor)
+	return h
+}
+
+// TestFactEdgesIndependentOfOrder 实体对两个方向都有事实时都是 fact 边，与三元组和文档的插入顺序无关；
+// 删除一个方向的事实后，该方向退回 fact_back 边
+func TestFactEdgesIndependentOfOrder(t *testing.T) {
+	ctx := context.Background()
+	reversed := []document.Document{mentorDocs[1], mentorDocs[0]}
+	tests := []struct {
+		name    string
+		batches [][]document.Document
+	}{
+		{"one batch", [][]document.Document{mentorDocs}},
+		{"one batch reversed", [][]document.Document{reversed}},
+		{"two batches", [][]document.Document{mentorDocs[:1], mentorDocs[1:]}},
+		{"two batches reversed", [][]document.Document{reversed[:1], reversed[1:]}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newMentorHippoRAG(t)
+			for _, batch := range tt.batches {
+				if err := h.Insert(ctx, batch); err != nil {
+					t.Fatalf("Insert: %v", err)
+				}
+			}
+			if got := edgeType(h, "玻尔", "卢瑟福"); got != "fact" {
+				t.Errorf("玻尔 -> 卢瑟福: got %q, want fact", got)
+			}
+			if got := edgeType(h, "卢瑟福", "玻尔"); got != "fact" {
+				t.Errorf("卢瑟福 -> 玻尔: got %q, want fact", got)
+			}
+
+			if err := h.DeleteDocuments(ctx, []string{"mentor"}); err != nil {
+				t.Fatalf("DeleteDocuments: %v", err)
+			}
+			assertConsistent(t, h)
+			if got := edgeType(h, "玻尔", "卢瑟福"); got != "fact" {
+				t.Errorf("玻尔 -> 卢瑟福 after delete: got %q, want fact", got)
+			}
+			if got := edgeType(h, "卢瑟福", "玻尔"); got != "fact_back" {
+				t.Errorf("卢瑟福 -> 玻尔 after delete: got %q, want fact_back", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package hipporag
+
+// persist.go - 索引持久化
+// 用途：把构建好的索引（知识图谱 + 文档块/实体/事实向量存储）保存到目录，
+// 重启后直接加载，无需重新执行 OpenIE 和向量化
+// 目录结构：
+// - graph.json: 知识图谱
+// - chunks.json / entities.json / facts.json: 三个向量存储
+// - fact_triples.json: 事实的结构化三元组
+// - catalog.json: 文档 -> 文档块 -> 实体/事实 的来源关系
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// 索引目录中的文件名
+const (
+	graphFileName    = "graph.json"
+	chunksFileName   = "chunks.json"
+	entitiesFileName = "entities.json"
+	factsFileName    = "facts.json"
+	triplesFileName  = "fact_triples.json"
+	catalogFileName  = "catalog.json"
+)
+
+// persistentStore 支持保存到本地文件的向量存储（如内存存储 embedding.Store）
+// Weaviate 等外部存储自身负责持久化，保存和加载时会跳过
+type persistentStore interface {
+	Save(path string) error
+	Load(path string) error
+}
+
+// Save 将索引保存到目录 dir（不存在时自动创建）
+func (h *HippoRAG) Save(dir string) error {
+	h.indexMu.Lock()
+	defer h.indexMu.Unlock()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create index dir: %w", err)
+	}
+
+	file, err := os.Create(filepath.Join(dir, graphFileName))
+	if err != nil {
+		return fmt.Errorf("create graph file: %w", err)
+	}
+	if err := h.graph.Save(file); err != nil {
+		file.Close()
+		return fmt.Errorf("save graph: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close graph file: %w", err)
+	}
+
+	for name, store := range h.persistentStores() {
+		if err := store.Save(filepath.Join(dir, name)); err != nil {
+			return fmt.Errorf("save %s: %w", name, err)
+		}
+	}
+
+	if err := h.facts.save(filepath.Join(dir, triplesFileName)); err != nil {
+		return fmt.Errorf("save fact triples: %w", err)
+	}
+
+	if err := h.catalog.save(filepath.Join(dir, catalogFileName)); err != nil {
+		return fmt.Errorf("save catalog: %w", err)
+	}
+
+	return nil
+}
+
+// Load 从目录 dir 加载索引，加载完成后即可检索
+func (h *HippoRAG) Load(dir string) error {
+	h.indexMu.Lock()
+	defer h.indexMu.Unlock()
+
+	file, err := os.Open(filepath.Join(dir, graphFileName))
+	if err != nil {
+		return fmt.Errorf("open graph file: %w", err)
+	}
+	defer file.Close()
+
+	if err := h.graph.Load(file); err != nil {
+		return fmt.Errorf("load graph: %w", err)
+	}
+
+	for name, store := range h.persistentStores() {
+		if err := store.Load(filepath.Join(dir, name)); err != nil {
+			return fmt.Errorf("load %s: %w", name, err)
+		}
+	}
+
+	// 先清空当前的三元组和来源关系，旧版本的索引缺少对应文件时不会残留加载前的数据
+	h.facts.reset()
+	h.catalog.reset()
+
+	// 旧版本保存的索引没有 fact_triples.json，此时事实检索不会产生种子
+	triplesPath := filepath.Join(dir, triplesFileName)
+	if _, err := os.Stat(triplesPath); err == nil {
+		if err := h.facts.load(triplesPath); err != nil {
+			return fmt.Errorf("load fact triples: %w", err)
+		}
+	}
+
+	// 旧版本保存的索引没有 catalog.json，此时无法删除其中的文档
+	catalogPath := filepath.Join(dir, catalogFileName)
+	if _, err := os.Stat(catalogPath); err == nil {
+		if err := h.catalog.load(catalogPath); err != nil {
+			return fmt.Errorf("load catalog: %w", err)
+		}
+	}
+
+	h.readyToRetrieve.Store(true)
+	return nil
+}
+
+// persistentStores 返回支持本地持久化的存储：文件名 -> 存储
+func (h *HippoRAG) persistentStores() map[string]persistentStore {
+	stores := make(map[string]persistentStore)
+	if store, ok := h.chunkStore.(persistentStore); ok {
+		stores[chunksFileName] = store
+	}
+	if store, ok := h.entityStore.(persistentStore); ok {
+		stores[entitiesFileName] = store
+	}
+	if store, ok := h.facts.vectors.(persistentStore); ok {
+		stores[factsFileName] = store
+	}
+	return stores
+}
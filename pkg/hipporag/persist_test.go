@@ -0,0 +1,72 @@
+package hipporag
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestLoadReplacesIndex 加载到已有数据的实例时完全替换原有索引
+func TestLoadReplacesIndex(t *testing.T) {
+	ctx := context.Background()
+	saved := newOfflineHippoRAG()
+	if err := saved.Insert(ctx, offlineDocs[:1]); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	dir := t.TempDir()
+	if err := saved.Save(dir); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	h := newOfflineHippoRAG()
+	if err := h.Insert(ctx, offlineDocs[1:]); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if err := h.Load(dir); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	assertConsistent(t, h)
+	if !reflect.DeepEqual(h.Documents(), []string{"einstein"}) {
+		t.Errorf("Documents after Load: got %v, want [einstein]", h.Documents())
+	}
+	if !reflect.DeepEqual(h.Stats(ctx), saved.Stats(ctx)) {
+		t.Errorf("Stats after Load: got %v, want %v", h.Stats(ctx), saved.Stats(ctx))
+	}
+}
+
+// TestLoadLegacyIndex 旧版本的索引没有三元组和来源关系文件，加载后不残留加载前的事实和文档
+func TestLoadLegacyIndex(t *testing.T) {
+	ctx := context.Background()
+	saved := newOfflineHippoRAG()
+	if err := saved.Insert(ctx, offlineDocs[:1]); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	dir := t.TempDir()
+	if err := saved.Save(dir); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	for _, name := range []string{triplesFileName, catalogFileName} {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h := newOfflineHippoRAG()
+	if err := h.Insert(ctx, offlineDocs[1:]); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if err := h.Load(dir); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if size := h.facts.Size(); size != 0 {
+		t.Errorf("fact triples after Load: got %d, want 0", size)
+	}
+	if docs := h.Documents(); len(docs) != 0 {
+		t.Errorf("Documents after Load: got %v, want none", docs)
+	}
+	if _, err := h.Retrieve(ctx, []string{"谁提出了相对论？"}, 1); err != nil {
+		t.Errorf("Retrieve after Load: %v", err)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"context"
 	"fmt"
 	"strings"
+
+	"github.com/example/go-scaffold/pkg/prompt"
 )
 
 // Query 问答：检索 + 生成答案
@@ -29,19 +31,13 @@ func (h *HippoRAG) Query(ctx context.Context, query string) (string, error) {
 	// 构造提示词
 	fmt.Println("\n步骤 5: 使用 LLM 生成答案...")
 	context := strings.Join(solution.ChunkTexts, "\n")
-	prompt := fmt.Sprintf(`基于以下文档回答问题。请仔细阅读所有文档，找出相关信息并进行推理。
-
-文档:
-%s
-
-问题: %s
-
-请一步步思考，然后给出简洁的答案。
-
-答案:`, context, query)
+	p, err := h.prompts.Render(prompt.QA, map[string]any{"Context": context, "Question": query})
+	if err != nil {
+		return "", err
+	}
 
 	// 生成答案
-	answer, err := h.llmClient.Complete(ctx, prompt)
+	answer, err := h.llmClient.Complete(ctx, p)
 	if err != nil {
 		return "", fmt.Errorf("generate answer: %w", err)
 	}
@@ -72,19 +68,13 @@ func (h *HippoRAG) QueryFull(ctx context.Context, query string) (string, error)
 	// 构造提示词
 	fmt.Println("\n步骤 8: 使用 LLM 生成答案...")
 	context := strings.Join(solution.ChunkTexts, "\n")
-	prompt := fmt.Sprintf(`基于以下文档回答问题。请仔细阅读所有文档，找出相关信息并进行推理。
-
-文档:
-%s
-
-问题: %s
-
-请一步步思考，然后给出简洁的答案。
-
-答案:`, context, query)
+	p, err := h.prompts.Render(prompt.QA, map[string]any{"Context": context, "Question": query})
+	if err != nil {
+		return "", err
+	}
 
 	// 生成答案
-	answer, err := h.llmClient.Complete(ctx, prompt)
+	answer, err := h.llmClient.Complete(ctx, p)
 	if err != nil {
 		return "", fmt.Errorf("generate answer: %w", err)
 	}
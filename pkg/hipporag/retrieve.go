@@ -17,7 +17,7 @@ import (
 // queries: 查询列表
 // topK: 返回的文档块数量
 func (h *HippoRAG) Retrieve(ctx context.Context, queries []string, topK int) ([]QuerySolution, error) {
-	if !h.readyToRetrieve {
+	if !h.readyToRetrieve.Load() {
 		return nil, fmt.Errorf("index not ready, please call Index first")
 	}
 	
@@ -60,12 +60,7 @@ func (h *HippoRAG) Retrieve(ctx context.Context, queries []string, topK int) ([]
 		}
 		
 		// 执行 PPR
-		pprScores := h.graph.PPR(
-			seedWeights,
-			h.config.PPRDamping,
-			h.config.PPRMaxIter,
-			h.config.PPRTolerance,
-		)
+		pprScores := h.runPPR(seedWeights)
 		
 		fmt.Printf("✓ PPR 完成，计算了 %d 个节点的分数\n", len(pprScores))
 		
@@ -97,6 +92,7 @@ func (h *HippoRAG) Retrieve(ctx context.Context, queries []string, topK int) ([]
 		chunkIDs := make([]string, topK)
 		chunkTexts := make([]string, topK)
 		scores := make([]float64, topK)
+		retrieved := make([]RetrievedChunk, topK)
 		
 		fmt.Println("\n检索到的文档块:")
 		fmt.Println("---")
@@ -105,6 +101,7 @@ func (h *HippoRAG) Retrieve(ctx context.Context, queries []string, topK int) ([]
 			scores[j] = chunks[j].score
 			content, _ := h.chunkStore.GetContent(ctx, chunks[j].id)
 			chunkTexts[j] = content
+			retrieved[j] = h.newRetrievedChunk(chunkIDs[j], content, scores[j])
 			fmt.Printf("%d. [PPR分数: %.6f] %s\n", j+1, scores[j], content)
 		}
 		fmt.Println("---")
@@ -114,8 +111,63 @@ func (h *HippoRAG) Retrieve(ctx context.Context, queries []string, topK int) ([]
 			ChunkIDs:   chunkIDs,
 			ChunkTexts: chunkTexts,
 			Scores:     scores,
+			Chunks:     retrieved,
 		}
 	}
 	
 	return solutions, nil
-}
\ No newline at end of file
+}
+
+// runPPR 按配置在知识图谱上执行 PPR
+func (h *HippoRAG) runPPR(seedWeights map[string]float64) map[string]float64 {
+	if h.config.PPRMode == PPRModeApprox {
+		// 手工构造的 Config 未设置阈值时使用默认值，否则近似 PPR 不会推送任何残差
+		epsilon := h.config.PPREpsilon
+		if epsilon <= 0 {
+			epsilon = defaultPPREpsilon
+		}
+		return h.graph.ApproxPPR(
+			seedWeights,
+			h.config.PPRDamping,
+			epsilon,
+			h.config.PPRWeighted,
+			h.config.PPREdgeTypeWeights,
+		)
+	}
+
+	if !h.config.PPRDense {
+		csr := h.graph.CSR()
+		if h.config.PPRWeighted {
+			return csr.PPR(
+				seedWeights,
+				h.config.PPRDamping,
+				h.config.PPRMaxIter,
+				h.config.PPRTolerance,
+				h.config.PPREdgeTypeWeights,
+			)
+		}
+		return csr.UniformPPR(
+			seedWeights,
+			h.config.PPRDamping,
+			h.config.PPRMaxIter,
+			h.config.PPRTolerance,
+		)
+	}
+
+	if h.config.PPRWeighted {
+		return h.graph.WeightedPPR(
+			seedWeights,
+			h.config.PPRDamping,
+			h.config.PPRMaxIter,
+			h.config.PPRTolerance,
+			h.config.PPREdgeTypeWeights,
+		)
+	}
+
+	return h.graph.PPR(
+		seedWeights,
+		h.config.PPRDamping,
+		h.config.PPRMaxIter,
+		h.config.PPRTolerance,
+	)
+}
@@ -10,12 +10,13 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/example/go-scaffold/pkg/prompt"
 	"github.com/example/go-scaffold/pkg/utils"
 )
 
 // RetrieveFull 完整版检索（包含所有步骤）
 func (h *HippoRAG) RetrieveFull(ctx context.Context, queries []string, topK int) ([]QuerySolution, error) {
-	if !h.readyToRetrieve {
+	if !h.readyToRetrieve.Load() {
 		return nil, fmt.Errorf("index not ready, please call Index first")
 	}
 
@@ -48,54 +49,50 @@ func (h *HippoRAG) RetrieveFull(ctx context.Context, queries []string, topK int)
 		// ========== 步骤 3: 事实检索 ==========
 		fmt.Printf("\n步骤 3: 事实检索 (Top-%d)...\n", h.config.TopKEntities)
 
-		factIDs, factScores, err := h.factStore.Search(ctx, queryVecForFact, h.config.TopKEntities)
+		facts, factScores, err := h.facts.Search(ctx, queryVecForFact, h.config.TopKEntities)
 		if err != nil {
 			return nil, fmt.Errorf("search facts: %w", err)
 		}
 
 		fmt.Println("找到的相关事实:")
-		for j, id := range factIDs {
-			content, _ := h.factStore.GetContent(ctx, id)
-			fmt.Printf("  %d. [分数: %.4f] %s\n", j+1, factScores[j], content)
+		for j, fact := range facts {
+			fmt.Printf("  %d. [分数: %.4f] %s\n", j+1, factScores[j], fact.Text())
 		}
 
 		// ========== 步骤 4: 事实重排序（Recognition Memory）==========
 		fmt.Println("\n步骤 4: LLM 重排序事实...")
 
 		// 构建重排序 prompt
-		var factsText strings.Builder
-		for j, id := range factIDs {
-			content, _ := h.factStore.GetContent(ctx, id)
-			factsText.WriteString(fmt.Sprintf("%d. %s\n", j+1, content))
+		factTexts := make([]string, len(facts))
+		for j, fact := range facts {
+			factTexts[j] = fact.Text()
 		}
+		rerankerPrompt, promptErr := h.prompts.Render(prompt.Rerank, map[string]any{"Query": query, "Facts": factTexts})
 
-		rerankerPrompt := fmt.Sprintf(`给定查询："%s"
-
-请对以下事实按相关性排序（最相关的排在前面）：
-%s
-
-只返回排序后的序号，用逗号分隔。例如：3,1,4,2,5
-
-排序结果：`, query, factsText.String())
-
-		rerankedIndices := factIDs // 默认不重排序
+		// 重排序结果：事实在 facts 中的下标
+		reranked := make([]int, len(facts)) // 默认不重排序
+		for j := range facts {
+			reranked[j] = j
+		}
 
 		// 调用 LLM 重排序（可选，如果 LLM 调用失败则跳过）
-		if response, err := h.llmClient.Complete(ctx, rerankerPrompt); err == nil {
+		if promptErr != nil {
+			fmt.Printf("⚠️  %v，使用原始排序\n", promptErr)
+		} else if response, err := h.llmClient.Complete(ctx, rerankerPrompt); err == nil {
 			// 解析 LLM 返回的排序
 			parts := strings.Split(strings.TrimSpace(response), ",")
 			if len(parts) > 0 {
-				newIndices := make([]string, 0, len(parts))
+				newIndices := make([]int, 0, len(parts))
 				for _, part := range parts {
 					var idx int
 					if _, err := fmt.Sscanf(strings.TrimSpace(part), "%d", &idx); err == nil {
-						if idx > 0 && idx <= len(factIDs) {
-							newIndices = append(newIndices, factIDs[idx-1])
+						if idx > 0 && idx <= len(facts) {
+							newIndices = append(newIndices, idx-1)
 						}
 					}
 				}
 				if len(newIndices) > 0 {
-					rerankedIndices = newIndices
+					reranked = newIndices
 					fmt.Println("✓ LLM 重排序完成")
 				}
 			}
@@ -120,27 +117,21 @@ func (h *HippoRAG) RetrieveFull(ctx context.Context, queries []string, topK int)
 		// ========== 步骤 6: 图搜索与 PPR ==========
 		fmt.Println("\n步骤 6: 图搜索与 PPR...")
 
-		// 6.1 从重排序后的事实中提取实体
+		// 6.1 以重排序后事实的主语和宾语实体作为种子
 		entityWeights := make(map[string]float64)
+		used := make(map[int]bool)
 
-		for rank, factID := range rerankedIndices {
-			if rank >= len(factScores) {
-				break
+		for _, idx := range reranked {
+			if used[idx] {
+				continue
 			}
+			used[idx] = true
 
-			// 获取事实内容
-			factContent, _ := h.factStore.GetContent(ctx, factID)
-
-			// 解析三元组（简化版：假设格式为 "[subject, predicate, object]"）
-			// 实际应该从索引时保存的映射中获取
-			entities := extractEntitiesFromFact(factContent)
-
-			// 为每个实体分配权重
-			factScore := factScores[rank]
-			for _, entity := range entities {
-				entityID := utils.ComputeHash(entity, "entity-")
+			// 主语和宾语平分该事实的相似度分数
+			fact := facts[idx]
+			for _, entityID := range []string{fact.SubjectID, fact.ObjectID} {
 				if _, exists := h.graph.GetNode(entityID); exists {
-					entityWeights[entityID] += factScore / float64(len(entities))
+					entityWeights[entityID] += factScores[idx] / 2
 				}
 			}
 		}
@@ -166,12 +157,7 @@ func (h *HippoRAG) RetrieveFull(ctx context.Context, queries []string, topK int)
 		fmt.Printf("  参数: damping=%.2f, maxIter=%d, tolerance=%.0e\n",
 			h.config.PPRDamping, h.config.PPRMaxIter, h.config.PPRTolerance)
 
-		pprScores := h.graph.PPR(
-			entityWeights,
-			h.config.PPRDamping,
-			h.config.PPRMaxIter,
-			h.config.PPRTolerance,
-		)
+		pprScores := h.runPPR(entityWeights)
 
 		fmt.Printf("✓ PPR 完成，计算了 %d 个节点的分数\n", len(pprScores))
 
@@ -204,6 +190,7 @@ func (h *HippoRAG) RetrieveFull(ctx context.Context, queries []string, topK int)
 		resultChunkIDs := make([]string, topK)
 		resultChunkTexts := make([]string, topK)
 		resultScores := make([]float64, topK)
+		retrieved := make([]RetrievedChunk, topK)
 
 		fmt.Println("\n最终检索结果:")
 		fmt.Println("---")
@@ -212,6 +199,7 @@ func (h *HippoRAG) RetrieveFull(ctx context.Context, queries []string, topK int)
 			resultScores[j] = chunks[j].score
 			content, _ := h.chunkStore.GetContent(ctx, chunks[j].id)
 			resultChunkTexts[j] = content
+			retrieved[j] = h.newRetrievedChunk(resultChunkIDs[j], content, resultScores[j])
 			fmt.Printf("%d. [PPR分数: %.6f] %s\n", j+1, resultScores[j], content)
 		}
 		fmt.Println("---")
@@ -221,34 +209,9 @@ func (h *HippoRAG) RetrieveFull(ctx context.Context, queries []string, topK int)
 			ChunkIDs:   resultChunkIDs,
 			ChunkTexts: resultChunkTexts,
 			Scores:     resultScores,
+			Chunks:     retrieved,
 		}
 	}
 
 	return solutions, nil
 }
-
-// extractEntitiesFromFact 从事实字符串中提取实体
-// 简化版实现，实际应该从索引时保存的映射中获取
-func extractEntitiesFromFact(factContent string) []string {
-	// 移除方括号和引号
-	factContent = strings.Trim(factContent, "[]")
-	factContent = strings.ReplaceAll(factContent, "'", "")
-	factContent = strings.ReplaceAll(factContent, "\"", "")
-
-	// 按逗号分割
-	parts := strings.Split(factContent, ",")
-
-	entities := make([]string, 0)
-	for i, part := range parts {
-		part = strings.TrimSpace(part)
-		// 跳过谓词（中间的部分）
-		if i == 1 {
-			continue
-		}
-		if part != "" {
-			entities = append(entities, part)
-		}
-	}
-
-	return entities
-}
@@ -0,0 +1,47 @@
+package hipporag
+
+import (
+	"math"
+	"testing"
+)
+
+// TestRunPPRDefaultEpsilon 手工构造的 Config 未设置 PPREpsilon 时，近似 PPR 使用默认阈值而不是返回空结果
+func TestRunPPRDefaultEpsilon(t *testing.T) {
+	h := NewHippoRAG(&Config{PPRMode: PPRModeApprox, PPRDamping: 0.5}, nil, nil)
+	h.graph.AddNode("e1", "爱因斯坦", "entity")
+	h.graph.AddNode("c1", "爱因斯坦提出了相对论。", "chunk")
+	h.graph.AddEdge("e1", "c1", 1.0, "passage_back")
+	h.graph.AddEdge("c1", "e1", 1.0, "passage")
+
+	scores := h.runPPR(map[string]float64{"e1": 1})
+	if scores["c1"] <= 0 {
+		t.Errorf("chunk score = %v, want > 0 (scores: %v)", scores["c1"], scores)
+	}
+}
+
+// TestRunPPRDense 零值 Config 在 CSR 快照上执行 PPR，与 PPRDense 的 map 实现结果一致
+func TestRunPPRDense(t *testing.T) {
+	sparse := NewHippoRAG(&Config{PPRDamping: 0.5, PPRMaxIter: 100, PPRTolerance: 1e-9}, nil, nil)
+	dense := NewHippoRAG(&Config{PPRDamping: 0.5, PPRMaxIter: 100, PPRTolerance: 1e-9, PPRDense: true}, nil, nil)
+	for _, h := range []*HippoRAG{sparse, dense} {
+		h.graph.AddNode("e1", "爱因斯坦", "entity")
+		h.graph.AddNode("e2", "相对论", "entity")
+		h.graph.AddNode("c1", "爱因斯坦提出了相对论。", "chunk")
+		h.graph.AddEdge("e1", "e2", 1.0, "fact")
+		h.graph.AddEdge("e2", "e1", 0.5, "fact_back")
+		h.graph.AddEdge("e1", "c1", 1.0, "passage_back")
+		h.graph.AddEdge("c1", "e1", 1.0, "passage")
+	}
+
+	seeds := map[string]float64{"e1": 1}
+	sparseScores := sparse.runPPR(seeds)
+	denseScores := dense.runPPR(seeds)
+	for _, id := range []string{"e1", "e2", "c1"} {
+		if math.Abs(sparseScores[id]-denseScores[id]) > 1e-6 {
+			t.Errorf("score of %s: sparse %v, dense %v", id, sparseScores[id], denseScores[id])
+		}
+	}
+	if sparseScores["c1"] <= 0 {
+		t.Errorf("chunk score = %v, want > 0", sparseScores["c1"])
+	}
+}
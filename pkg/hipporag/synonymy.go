@@ -0,0 +1,132 @@
+package hipporag
+
+// synonymy.go - 同义边构建
+// 用途：在语义相近的实体之间添加 synonymy 边（如 "爱因斯坦" 与 "阿尔伯特·爱因斯坦"），
+// 让 PPR 能在同一事物的不同写法之间传播分数
+// 主要功能：
+// - 对新增实体在实体向量存储中做 k 近邻搜索
+// - 相似度不低于 SynonymyThreshold 的邻居添加双向 synonymy 边，权重为相似度
+// - 每个实体最多连接 SynonymyMaxNeighbors 个邻居
+// - 增量执行：只处理本次新增的实体，已有实体作为邻居被连接
+// - 图中每个方向只保留一条边：已有 fact / fact_back 边的实体对不添加同义边，
+//   删除文档回收了这样的事实边后，由 relinkSynonyms 按相似度补上同义边
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/example/go-scaffold/pkg/graph"
+	"github.com/example/go-scaffold/pkg/utils"
+)
+
+// addSynonymyEdges 为新增实体在子图 sub 中添加 synonymy 边
+// entityIDs: 本次新增的实体 ID（节点需已加入 sub）
+// 返回：添加的实体对数量
+func (h *HippoRAG) addSynonymyEdges(ctx context.Context, sub *graph.Graph, entityIDs []string) (int, error) {
+	maxNeighbors := h.config.SynonymyMaxNeighbors
+	if maxNeighbors <= 0 {
+		return 0, nil
+	}
+
+	pairs := make(map[[2]string]bool)
+	for _, entityID := range entityIDs {
+		vec, err := h.entityStore.Get(ctx, entityID)
+		if err != nil {
+			return 0, fmt.Errorf("get entity %s: %w", entityID, err)
+		}
+
+		// 多取一个结果，因为实体自身总是最近邻
+		neighborIDs, scores, err := h.entityStore.Search(ctx, vec, maxNeighbors+1)
+		if err != nil {
+			return 0, fmt.Errorf("search neighbors of %s: %w", entityID, err)
+		}
+
+		added := 0
+		for i, neighborID := range neighborIDs {
+			if added >= maxNeighbors {
+				break
+			}
+			if neighborID == entityID || scores[i] < h.config.SynonymyThreshold {
+				continue
+			}
+
+			// 邻居可能是图谱中已有的实体，先把它加入子图才能连边
+			if _, exists := sub.GetNode(neighborID); !exists {
+				node, exists := h.graph.GetNode(neighborID)
+				if !exists || node.Type != "entity" {
+					continue
+				}
+				sub.AddNode(node.ID, node.Content, node.Type)
+			}
+
+			// 已有事实边的实体对不再添加同义边，否则会覆盖事实边
+			if h.linkedByFact(sub, entityID, neighborID) {
+				continue
+			}
+
+			sub.AddEdge(entityID, neighborID, scores[i], "synonymy")
+			sub.AddEdge(neighborID, entityID, scores[i], "synonymy")
+			pairs[pairKey(entityID, neighborID)] = true
+			added++
+		}
+	}
+
+	return len(pairs), nil
+}
+
+// linkedByFact 判断实体对之间（任一方向）是否已有非 synonymy 的边，在子图 sub 或已有图谱中
+func (h *HippoRAG) linkedByFact(sub *graph.Graph, a, b string) bool {
+	for _, g := range []*graph.Graph{sub, h.graph} {
+		for _, pair := range [][2]string{{a, b}, {b, a}} {
+			if edge, exists := g.GetEdge(pair[0], pair[1]); exists && edge.Type != "synonymy" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// relinkSynonyms 实体对之间的 fact 边被删除后，两个实体都还在且相似度不低于阈值时补上双向 synonymy 边
+// （索引时这些实体对因已有事实边而没有同义边）；调用方需持有 indexMu
+// 返回：添加的实体对数量
+func (h *HippoRAG) relinkSynonyms(ctx context.Context, pairs [][2]string) (int, error) {
+	if h.config.SynonymyMaxNeighbors <= 0 {
+		return 0, nil
+	}
+
+	linked := 0
+	for _, pair := range pairs {
+		a, b := pair[0], pair[1]
+		if _, exists := h.graph.GetNode(a); !exists {
+			continue
+		}
+		if _, exists := h.graph.GetNode(b); !exists {
+			continue
+		}
+		if _, exists := h.graph.GetEdge(a, b); exists {
+			continue
+		}
+		if _, exists := h.graph.GetEdge(b, a); exists {
+			continue
+		}
+
+		vecA, err := h.entityStore.Get(ctx, a)
+		if err != nil {
+			return linked, fmt.Errorf("get entity %s: %w", a, err)
+		}
+		vecB, err := h.entityStore.Get(ctx, b)
+		if err != nil {
+			return linked, fmt.Errorf("get entity %s: %w", b, err)
+		}
+
+		score := utils.CosineSimilarity(vecA, vecB)
+		if score < h.config.SynonymyThreshold {
+			continue
+		}
+		h.graph.AddEdge(a, b, score, "synonymy")
+		h.graph.AddEdge(b, a, score, "synonymy")
+		linked++
+	}
+
+	return linked, nil
+}
@@ -0,0 +1,64 @@
+package hipporag
+
+import (
+	"context"
+	"testing"
+
+	"github.com/example/go-scaffold/pkg/document"
+)
+
+// TestSynonymyKeepsFactEdges 同义实体之间已有事实时保留 fact / fact_back 边，不被 synonymy 边覆盖
+func TestSynonymyKeepsFactEdges(t *testing.T) {
+	ctx := context.Background()
+	factDoc := document.Document{ID: "a", Text: "爱因斯坦是阿尔伯特·爱因斯坦。"}
+	synonymDoc := document.Document{ID: "b", Text: "爱因斯坦提出了相对论。阿尔伯特·爱因斯坦出生于乌尔姆。"}
+
+	tests := []struct {
+		name          string
+		batches       [][]document.Document
+		forward, back string
+	}{
+		// 同一批次中先建事实边，再加同义边
+		{"fact in same batch", [][]document.Document{{factDoc, synonymDoc}}, "fact", "fact_back"},
+		// 只有同义关系
+		{"synonyms only", [][]document.Document{{synonymDoc}}, "synonymy", "synonymy"},
+		// 已有同义边后增量添加事实：事实边优先
+		{"fact added later", [][]document.Document{{synonymDoc}, {factDoc}}, "fact", "fact_back"},
+		// 已有事实边后增量添加同义文档：新实体不会覆盖已有事实边
+		{"synonyms added later", [][]document.Document{{factDoc}, {synonymDoc}}, "fact", "fact_back"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newOfflineHippoRAG()
+			for _, batch := range tt.batches {
+				if err := h.Insert(ctx, batch); err != nil {
+					t.Fatalf("Insert: %v", err)
+				}
+			}
+			assertConsistent(t, h)
+
+			forward := edgeType(h, "爱因斯坦", "阿尔伯特·爱因斯坦")
+			back := edgeType(h, "阿尔伯特·爱因斯坦", "爱因斯坦")
+			if forward != tt.forward || back != tt.back {
+				t.Errorf("edge types: got %q / %q, want %q / %q", forward, back, tt.forward, tt.back)
+			}
+			if got := edgeType(h, "爱因斯坦", "相对论"); got != "fact" {
+				t.Errorf("爱因斯坦 -> 相对论: got %q, want fact", got)
+			}
+		})
+	}
+
+	// 删除唯一的事实后，两个实体仍由其他文档支撑，按相似度恢复同义边
+	h := newOfflineHippoRAG()
+	if err := h.Insert(ctx, []document.Document{factDoc, synonymDoc}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if err := h.DeleteDocuments(ctx, []string{"a"}); err != nil {
+		t.Fatalf("DeleteDocuments: %v", err)
+	}
+	assertConsistent(t, h)
+	if forward, back := edgeType(h, "爱因斯坦", "阿尔伯特·爱因斯坦"), edgeType(h, "阿尔伯特·爱因斯坦", "爱因斯坦"); forward != "synonymy" || back != "synonymy" {
+		t.Errorf("after deleting the fact: got %q / %q, want synonymy in both directions", forward, back)
+	}
+}
@@ -0,0 +1,38 @@
+package llm
+
+// client.go - LLM 客户端接口定义
+// 用途：定义统一的 LLM 接口，支持不同的服务（OpenAI、本地模型、缓存、测试用假客户端等）
+// 主要功能：
+// - Client 接口：Complete（单轮文本生成）和 Chat（多轮消息 + 调用参数）
+// - Message / ChatOptions: 对话消息和可选的调用参数
+
+import "context"
+
+// 消息角色
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message 对话消息
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ChatOptions 单次调用的可选参数，零值表示使用客户端默认设置
+type ChatOptions struct {
+	Temperature *float64 `json:"temperature,omitempty"` // 温度，nil 时使用客户端默认温度
+	MaxTokens   int      `json:"max_tokens,omitempty"`  // 最大生成 token 数，<= 0 时不限制
+	Stop        []string `json:"stop,omitempty"`        // 停止序列
+}
+
+// Client LLM 客户端接口
+type Client interface {
+	// Complete 根据单条用户提示生成文本
+	Complete(ctx context.Context, prompt string) (string, error)
+
+	// Chat 根据对话消息生成回复
+	Chat(ctx context.Context, messages []Message, opts ChatOptions) (string, error)
+}
@@ -4,7 +4,10 @@ package llm
 // 用途：调用 OpenAI API 进行文本生成（用于 OpenIE 和 QA）
 // 主要功能：
 // - Complete: 文本补全/生成
+// - Chat: 多轮对话，支持按次覆盖温度、最大 token 数和停止序列
 // - 支持自定义模型、温度等参数
+// - 429 / 5xx / 网络错误自动重试（指数退避 + 抖动，遵守 Retry-After），可选客户端限流
+// - 实现 Client 接口
 
 import (
 	"bytes"
@@ -15,6 +18,9 @@ import (
 	"net/http"
 	"os"
 	"time"
+
+	"github.com/example/go-scaffold/pkg/retry"
+	"github.com/example/go-scaffold/pkg/utils"
 )
 
 // OpenAIClient OpenAI LLM 客户端
@@ -24,6 +30,8 @@ type OpenAIClient struct {
 	temperature float64
 	baseURL     string
 	client      *http.Client
+	retryPolicy retry.Policy
+	limiter     *retry.Limiter // 可与其他客户端共享，nil 表示不限流
 }
 
 // NewOpenAIClient 创建 OpenAI 客户端
@@ -46,29 +54,42 @@ func NewOpenAIClient(apiKey, model string) *OpenAIClient {
 		client: &http.Client{
 			Timeout: 120 * time.Second,
 		},
+		retryPolicy: retry.DefaultPolicy(),
 	}
 }
 
+// Model 返回模型名
+func (c *OpenAIClient) Model() string {
+	return c.model
+}
+
 // SetTemperature 设置温度参数
 func (c *OpenAIClient) SetTemperature(temp float64) {
 	c.temperature = temp
 }
 
+// SetRetryPolicy 设置重试策略（默认 retry.DefaultPolicy，retry.NoRetry 关闭重试）
+func (c *OpenAIClient) SetRetryPolicy(policy retry.Policy) {
+	c.retryPolicy = policy
+}
+
+// SetRateLimiter 设置客户端限流器，同一个限流器可以在多个客户端之间共享
+func (c *OpenAIClient) SetRateLimiter(limiter *retry.Limiter) {
+	c.limiter = limiter
+}
+
 // OpenAI API 请求/响应结构
 type completionRequest struct {
 	Model       string    `json:"model"`
-	Messages    []message `json:"messages"`
+	Messages    []Message `json:"messages"`
 	Temperature float64   `json:"temperature"`
-}
-
-type message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	MaxTokens   int       `json:"max_tokens,omitempty"`
+	Stop        []string  `json:"stop,omitempty"`
 }
 
 type completionResponse struct {
 	Choices []struct {
-		Message message `json:"message"`
+		Message Message `json:"message"`
 	} `json:"choices"`
 	Error *struct {
 		Message string `json:"message"`
@@ -78,16 +99,26 @@ type completionResponse struct {
 
 // Complete 生成文本补全
 func (c *OpenAIClient) Complete(ctx context.Context, prompt string) (string, error) {
+	return c.Chat(ctx, []Message{
+		{
+			Role:    RoleUser,
+			Content: prompt,
+		},
+	}, ChatOptions{})
+}
+
+// Chat 根据对话消息生成回复
+func (c *OpenAIClient) Chat(ctx context.Context, messages []Message, opts ChatOptions) (string, error) {
 	// 构造请求
 	reqBody := completionRequest{
-		Model: c.model,
-		Messages: []message{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
+		Model:       c.model,
+		Messages:    messages,
 		Temperature: c.temperature,
+		MaxTokens:   opts.MaxTokens,
+		Stop:        opts.Stop,
+	}
+	if opts.Temperature != nil {
+		reqBody.Temperature = *opts.Temperature
 	}
 
 	jsonData, err := json.Marshal(reqBody)
@@ -95,6 +126,29 @@ func (c *OpenAIClient) Complete(ctx context.Context, prompt string) (string, err
 		return "", fmt.Errorf("marshal request: %w", err)
 	}
 
+	// 限流按提示词和最大生成长度估算 token 数
+	tokens := opts.MaxTokens
+	for _, msg := range messages {
+		tokens += utils.EstimateTokens(msg.Content)
+	}
+
+	var content string
+	err = retry.Do(ctx, c.retryPolicy, func(ctx context.Context) error {
+		if err := c.limiter.Wait(ctx, tokens); err != nil {
+			return err
+		}
+		content, err = c.send(ctx, jsonData)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return content, nil
+}
+
+// send 发送一次请求
+func (c *OpenAIClient) send(ctx context.Context, jsonData []byte) (string, error) {
 	// 发送请求
 	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewBuffer(jsonData))
 	if err != nil {
@@ -116,6 +170,10 @@ func (c *OpenAIClient) Complete(ctx context.Context, prompt string) (string, err
 		return "", fmt.Errorf("read response: %w", err)
 	}
 
+	if err := retry.CheckResponse(resp, body); err != nil {
+		return "", err
+	}
+
 	var compResp completionResponse
 	if err := json.Unmarshal(body, &compResp); err != nil {
 		return "", fmt.Errorf("unmarshal response: %w", err)
@@ -0,0 +1,273 @@
+package llm
+
+// replay.go - LLM 调用录制与回放
+// 用途：真实运行时录制 提示词 -> 回复，之后离线回放，用于可复现的流水线回归测试
+// 主要功能：
+// - Recorder: 包装任意 Client，记录每次调用的消息和回复，Save 保存为 fixture 文件
+// - Replayer: 从 fixture 文件回放回复，支持精确匹配或规范化匹配（忽略空白差异）
+// - 调用参数（ChatOptions）和模型名也是匹配条件，参数不同的调用不会拿到其他设置下录制的回复
+// - 找不到录制记录时返回错误（提示词或参数变化会被立即发现），并记录在 Misses 中
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// fixture 文件格式
+// 版本 2 起记录调用参数和模型名；版本 1 的记录按默认参数、未知模型回放
+const (
+	fixtureFormat  = "hipporag-llm-fixture"
+	fixtureVersion = 2
+)
+
+// ErrNoRecording 回放时找不到匹配的录制记录
+var ErrNoRecording = errors.New("no recorded completion")
+
+// Recording 一次录制的调用
+type Recording struct {
+	Model      string      `json:"model,omitempty"` // 被录制客户端的模型名（客户端提供 Model() 时）
+	Options    ChatOptions `json:"options"`
+	Messages   []Message   `json:"messages"`
+	Completion string      `json:"completion"`
+}
+
+type fixtureFile struct {
+	Format     string      `json:"format"`
+	Version    int         `json:"version"`
+	Recordings []Recording `json:"recordings"`
+}
+
+// MatchMode 回放时的提示词匹配方式
+type MatchMode string
+
+const (
+	// MatchExact 角色和内容完全一致
+	MatchExact MatchMode = "exact"
+	// MatchNormalized 忽略首尾空白，连续空白视为一个空格
+	MatchNormalized MatchMode = "normalized"
+)
+
+// Recorder 录制 LLM 调用的客户端（并发安全）
+type Recorder struct {
+	client     Client
+	model      string               // 被录制客户端的模型名
+	recordings map[string]Recording // 精确键 -> 录制记录（同一提示词和参数只保留第一次的回复）
+	mu         sync.Mutex
+}
+
+// NewRecorder 创建录制客户端，实际调用转发给 client
+// client 提供 Model() 方法（如 OpenAIClient）时，模型名随录制记录一起保存
+func NewRecorder(client Client) *Recorder {
+	r := &Recorder{
+		client:     client,
+		recordings: make(map[string]Recording),
+	}
+	if named, ok := client.(interface{ Model() string }); ok {
+		r.model = named.Model()
+	}
+	return r
+}
+
+// Complete 调用底层客户端并录制
+func (r *Recorder) Complete(ctx context.Context, prompt string) (string, error) {
+	completion, err := r.client.Complete(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	r.record([]Message{{Role: RoleUser, Content: prompt}}, ChatOptions{}, completion)
+	return completion, nil
+}
+
+// Chat 调用底层客户端并录制
+func (r *Recorder) Chat(ctx context.Context, messages []Message, opts ChatOptions) (string, error) {
+	completion, err := r.client.Chat(ctx, messages, opts)
+	if err != nil {
+		return "", err
+	}
+	r.record(messages, opts, completion)
+	return completion, nil
+}
+
+// record 保存一次调用
+func (r *Recorder) record(messages []Message, opts ChatOptions, completion string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := recordingKey(messages, opts, MatchExact)
+	if _, exists := r.recordings[key]; exists {
+		return
+	}
+	r.recordings[key] = Recording{
+		Model:      r.model,
+		Options:    opts,
+		Messages:   append([]Message(nil), messages...),
+		Completion: completion,
+	}
+}
+
+// Save 保存录制结果到 fixture 文件（按消息内容排序，便于比较差异）
+func (r *Recorder) Save(path string) error {
+	r.mu.Lock()
+	keys := make([]string, 0, len(r.recordings))
+	for key := range r.recordings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	file := fixtureFile{
+		Format:     fixtureFormat,
+		Version:    fixtureVersion,
+		Recordings: make([]Recording, len(keys)),
+	}
+	for i, key := range keys {
+		file.Recordings[i] = r.recordings[key]
+	}
+	r.mu.Unlock()
+
+	jsonData, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal data: %w", err)
+	}
+
+	if err := os.WriteFile(path, jsonData, 0644); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+
+	return nil
+}
+
+// Replayer 回放录制结果的客户端（并发安全）
+type Replayer struct {
+	mode       MatchMode
+	model      string                 // 只回放该模型的录制记录，为空时不限制
+	recordings map[string][]Recording // 匹配键 -> 录制记录（不同模型可能各有一条）
+	misses     []string               // 没有找到录制记录的提示词
+	mu         sync.Mutex
+}
+
+// LoadReplayer 从 fixture 文件创建回放客户端
+func LoadReplayer(path string, mode MatchMode) (*Replayer, error) {
+	if mode != MatchExact && mode != MatchNormalized {
+		return nil, fmt.Errorf("unknown match mode: %q", mode)
+	}
+
+	jsonData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	var file fixtureFile
+	if err := json.Unmarshal(jsonData, &file); err != nil {
+		return nil, fmt.Errorf("unmarshal data: %w", err)
+	}
+	if file.Format != fixtureFormat {
+		return nil, fmt.Errorf("unexpected fixture format: %q", file.Format)
+	}
+	if file.Version < 1 || file.Version > fixtureVersion {
+		return nil, fmt.Errorf("unsupported fixture version: %d", file.Version)
+	}
+
+	r := &Replayer{
+		mode:       mode,
+		recordings: make(map[string][]Recording),
+	}
+	for _, recording := range file.Recordings {
+		key := recordingKey(recording.Messages, recording.Options, mode)
+		r.recordings[key] = append(r.recordings[key], recording)
+	}
+
+	return r, nil
+}
+
+// SetModel 只回放模型名为 model 的录制记录（与 Recorder 包装的客户端的 Model() 比较）
+// 不设置时不检查模型名
+func (r *Replayer) SetModel(model string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.model = model
+}
+
+// Complete 回放单条提示词的回复
+func (r *Replayer) Complete(ctx context.Context, prompt string) (string, error) {
+	return r.Chat(ctx, []Message{{Role: RoleUser, Content: prompt}}, ChatOptions{})
+}
+
+// Chat 回放对话的回复，找不到录制记录时返回 ErrNoRecording
+func (r *Replayer) Chat(ctx context.Context, messages []Message, opts ChatOptions) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, recording := range r.recordings[recordingKey(messages, opts, r.mode)] {
+		if r.model == "" || recording.Model == r.model {
+			return recording.Completion, nil
+		}
+	}
+
+	prompt := ""
+	if len(messages) > 0 {
+		prompt = messages[len(messages)-1].Content
+	}
+	r.misses = append(r.misses, prompt)
+	return "", fmt.Errorf("%w for prompt: %q", ErrNoRecording, abbreviate(prompt, 120))
+}
+
+// Misses 返回没有找到录制记录的提示词（按调用顺序）
+// 调用方会吞掉 LLM 错误时（如重排序失败后使用原始排序），测试可以用它确认所有调用都被回放
+func (r *Replayer) Misses() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.misses...)
+}
+
+// recordingKey 按匹配方式生成 调用参数 + 消息列表 的查找键
+func recordingKey(messages []Message, opts ChatOptions, mode MatchMode) string {
+	var b strings.Builder
+
+	// 调用参数：温度（nil 表示客户端默认）、最大 token 数、停止序列
+	if opts.Temperature != nil {
+		b.WriteString(strconv.FormatFloat(*opts.Temperature, 'g', -1, 64))
+	}
+	b.WriteByte(0)
+	if opts.MaxTokens > 0 {
+		b.WriteString(strconv.Itoa(opts.MaxTokens))
+	}
+	b.WriteByte(0)
+	for _, stop := range opts.Stop {
+		b.WriteString(strconv.Quote(stop))
+	}
+	b.WriteByte(0)
+
+	for _, msg := range messages {
+		content := msg.Content
+		if mode == MatchNormalized {
+			content = strings.Join(strings.Fields(content), " ")
+		}
+		b.WriteString(msg.Role)
+		b.WriteByte(0)
+		b.WriteString(content)
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+// abbreviate 截断过长的文本（按字符）
+func abbreviate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
@@ -0,0 +1,153 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeClient 回复中包含提示词和参数，便于确认回放的是哪一次录制
+type fakeClient struct {
+	model string
+	calls int
+}
+
+func (c *fakeClient) Model() string {
+	return c.model
+}
+
+func (c *fakeClient) Complete(ctx context.Context, prompt string) (string, error) {
+	return c.Chat(ctx, []Message{{Role: RoleUser, Content: prompt}}, ChatOptions{})
+}
+
+func (c *fakeClient) Chat(ctx context.Context, messages []Message, opts ChatOptions) (string, error) {
+	c.calls++
+	return fmt.Sprintf("%s|%s|%d", c.model, messages[len(messages)-1].Content, opts.MaxTokens), nil
+}
+
+// recordFixture 录制几次调用并保存，返回 fixture 路径
+func recordFixture(t *testing.T) string {
+	t.Helper()
+	ctx := context.Background()
+	recorder := NewRecorder(&fakeClient{model: "gpt-4o-mini"})
+
+	if _, err := recorder.Complete(ctx, "爱因斯坦出生于哪里？"); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if _, err := recorder.Chat(ctx, []Message{{Role: RoleUser, Content: "抽取三元组"}}, ChatOptions{MaxTokens: 100}); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	if err := recorder.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	return path
+}
+
+// TestReplayRoundTrip 录制 → 保存 → 回放得到相同的回复
+func TestReplayRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	path := recordFixture(t)
+
+	replayer, err := LoadReplayer(path, MatchExact)
+	if err != nil {
+		t.Fatalf("LoadReplayer: %v", err)
+	}
+	replayer.SetModel("gpt-4o-mini")
+
+	got, err := replayer.Complete(ctx, "爱因斯坦出生于哪里？")
+	if err != nil || got != "gpt-4o-mini|爱因斯坦出生于哪里？|0" {
+		t.Errorf("Complete: got %q, %v", got, err)
+	}
+	got, err = replayer.Chat(ctx, []Message{{Role: RoleUser, Content: "抽取三元组"}}, ChatOptions{MaxTokens: 100})
+	if err != nil || got != "gpt-4o-mini|抽取三元组|100" {
+		t.Errorf("Chat: got %q, %v", got, err)
+	}
+	if misses := replayer.Misses(); len(misses) != 0 {
+		t.Errorf("Misses: got %v, want none", misses)
+	}
+}
+
+// TestReplayMiss 提示词、调用参数或模型不同时回放失败，并记录在 Misses 中
+func TestReplayMiss(t *testing.T) {
+	ctx := context.Background()
+	path := recordFixture(t)
+	temperature := 0.7
+
+	tests := []struct {
+		name     string
+		model    string
+		messages []Message
+		opts     ChatOptions
+	}{
+		{"prompt", "", []Message{{Role: RoleUser, Content: "抽取实体"}}, ChatOptions{MaxTokens: 100}},
+		{"role", "", []Message{{Role: RoleSystem, Content: "抽取三元组"}}, ChatOptions{MaxTokens: 100}},
+		{"max tokens", "", []Message{{Role: RoleUser, Content: "抽取三元组"}}, ChatOptions{MaxTokens: 200}},
+		{"temperature", "", []Message{{Role: RoleUser, Content: "抽取三元组"}}, ChatOptions{MaxTokens: 100, Temperature: &temperature}},
+		{"stop", "", []Message{{Role: RoleUser, Content: "抽取三元组"}}, ChatOptions{MaxTokens: 100, Stop: []string{"\n"}}},
+		{"model", "gpt-4o", []Message{{Role: RoleUser, Content: "抽取三元组"}}, ChatOptions{MaxTokens: 100}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replayer, err := LoadReplayer(path, MatchExact)
+			if err != nil {
+				t.Fatalf("LoadReplayer: %v", err)
+			}
+			replayer.SetModel(tt.model)
+
+			if _, err := replayer.Chat(ctx, tt.messages, tt.opts); !errors.Is(err, ErrNoRecording) {
+				t.Errorf("Chat: got %v, want ErrNoRecording", err)
+			}
+			if misses := replayer.Misses(); len(misses) != 1 || misses[0] != tt.messages[0].Content {
+				t.Errorf("Misses: got %q", misses)
+			}
+		})
+	}
+}
+
+// TestReplayNormalized 规范化匹配忽略空白差异，精确匹配不忽略
+func TestReplayNormalized(t *testing.T) {
+	ctx := context.Background()
+	path := recordFixture(t)
+	prompt := "  爱因斯坦出生于哪里？\n"
+
+	normalized, err := LoadReplayer(path, MatchNormalized)
+	if err != nil {
+		t.Fatalf("LoadReplayer: %v", err)
+	}
+	if _, err := normalized.Complete(ctx, prompt); err != nil {
+		t.Errorf("normalized: %v", err)
+	}
+
+	exact, err := LoadReplayer(path, MatchExact)
+	if err != nil {
+		t.Fatalf("LoadReplayer: %v", err)
+	}
+	if _, err := exact.Complete(ctx, prompt); !errors.Is(err, ErrNoRecording) {
+		t.Errorf("exact: got %v, want ErrNoRecording", err)
+	}
+}
+
+// TestReplayVersion1 版本 1 的 fixture 没有参数和模型名，按默认参数回放
+func TestReplayVersion1(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "v1.json")
+	data := `{"format": "hipporag-llm-fixture", "version": 1, "recordings": [
+		{"messages": [{"role": "user", "content": "你好"}], "completion": "你好！"}
+	]}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	replayer, err := LoadReplayer(path, MatchExact)
+	if err != nil {
+		t.Fatalf("LoadReplayer: %v", err)
+	}
+	if got, err := replayer.Complete(context.Background(), "你好"); err != nil || got != "你好！" {
+		t.Errorf("Complete: got %q, %v", got, err)
+	}
+}
@@ -0,0 +1,294 @@
+package loader
+
+// html.go - HTML 加载
+// 用途：从网页中提取正文文本
+// 主要功能：
+// - HTMLLoader: 每个文件作为一个文档，标题取 <title>（或第一个 <h1>），链接取 <link rel="canonical">
+// - 去掉模板内容：script / style、导航、页眉页脚、侧边栏、表单，以及 class / id 为 nav、menu、sidebar、cookie 等的元素
+// - 页面有 <main> 或 <article> 时只提取其中的内容
+// - 保留段落结构：块级元素之间空一行，标题转换为 Markdown 的 "#" 前缀（便于按段落 / 标题分块），列表项以 "- " 开头
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/example/go-scaffold/pkg/document"
+)
+
+// HTMLLoader HTML 加载器
+type HTMLLoader struct{}
+
+// skippedElements 不包含正文的元素
+var skippedElements = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Svg: true, atom.Iframe: true, atom.Nav: true, atom.Header: true, atom.Footer: true,
+	atom.Aside: true, atom.Form: true, atom.Button: true, atom.Select: true,
+}
+
+// boilerplateNames class / id 中出现这些词的元素视为模板内容
+var boilerplateNames = map[string]bool{
+	"nav": true, "navbar": true, "navigation": true, "menu": true, "sidebar": true,
+	"header": true, "footer": true, "breadcrumb": true, "breadcrumbs": true,
+	"cookie": true, "cookies": true, "banner": true, "ads": true, "advertisement": true,
+	"share": true, "social": true, "comments": true,
+}
+
+// paragraphElements 前后空一行的块级元素
+var paragraphElements = map[atom.Atom]bool{
+	atom.P: true, atom.Blockquote: true, atom.Ul: true, atom.Ol: true, atom.Dl: true,
+	atom.Table: true, atom.Section: true, atom.Article: true, atom.Main: true, atom.Figure: true,
+	atom.Hr: true, atom.Address: true,
+}
+
+// lineElements 前后换行的块级元素
+var lineElements = map[atom.Atom]bool{
+	atom.Div: true, atom.Tr: true, atom.Dt: true, atom.Dd: true, atom.Figcaption: true,
+	atom.Caption: true,
+}
+
+// headingLevels 标题元素的级别
+var headingLevels = map[atom.Atom]int{
+	atom.H1: 1, atom.H2: 2, atom.H3: 3, atom.H4: 4, atom.H5: 5, atom.H6: 6,
+}
+
+// Load 解析文件内容
+func (l *HTMLLoader) Load(r io.Reader, name string) ([]document.Document, error) {
+	root, err := html.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("parse html: %w", err)
+	}
+
+	content := findElement(root, atom.Main)
+	if content == nil {
+		content = findElement(root, atom.Article)
+	}
+	if content == nil {
+		content = findElement(root, atom.Body)
+	}
+	if content == nil {
+		content = root
+	}
+
+	// <main> / <article> 内的 <header> 通常是正文标题，保留
+	text := htmlText{keepHeader: content.DataAtom == atom.Main || content.DataAtom == atom.Article}
+	text.walk(content)
+
+	metadata := map[string]string{document.MetaSource: name}
+	title := ""
+	if node := findElement(root, atom.Title); node != nil {
+		title = strings.Join(strings.Fields(nodeText(node)), " ")
+	}
+	if title == "" {
+		if node := findElement(root, atom.H1); node != nil {
+			title = strings.Join(strings.Fields(nodeText(node)), " ")
+		}
+	}
+	if title == "" {
+		title = titleFromName(name)
+	}
+	metadata[document.MetaTitle] = title
+	if url := canonicalURL(root); url != "" {
+		metadata[document.MetaURL] = url
+	}
+
+	return []document.Document{{ID: name, Text: text.String(), Metadata: metadata}}, nil
+}
+
+// htmlText 从 HTML 节点中提取的文本
+type htmlText struct {
+	sb         strings.Builder
+	breaks     int  // 下一段文本之前待输出的换行数
+	space      bool // 下一段文本之前待输出空格
+	keepHeader bool // 是否保留 <header> 元素
+}
+
+// walk 提取节点及其子节点的文本
+func (t *htmlText) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		t.text(n.Data)
+		return
+	case html.ElementNode:
+		if isBoilerplate(n) && !(t.keepHeader && n.DataAtom == atom.Header) {
+			return
+		}
+	}
+
+	switch {
+	case n.DataAtom == atom.Br:
+		t.lineBreak(1)
+		return
+	case n.DataAtom == atom.Pre:
+		t.lineBreak(2)
+		t.write(strings.Trim(nodeText(n), "\n"))
+		t.lineBreak(2)
+		return
+	case headingLevels[n.DataAtom] > 0:
+		t.lineBreak(2)
+		t.write(strings.Repeat("#", headingLevels[n.DataAtom]) + " ")
+		t.children(n)
+		t.lineBreak(2)
+		return
+	case n.DataAtom == atom.Li:
+		t.lineBreak(1)
+		t.write("- ")
+		t.children(n)
+		t.lineBreak(1)
+		return
+	case n.DataAtom == atom.Td || n.DataAtom == atom.Th:
+		t.space = true
+		t.children(n)
+		t.space = true
+		return
+	case paragraphElements[n.DataAtom]:
+		t.lineBreak(2)
+		t.children(n)
+		t.lineBreak(2)
+		return
+	case lineElements[n.DataAtom]:
+		t.lineBreak(1)
+		t.children(n)
+		t.lineBreak(1)
+		return
+	}
+
+	t.children(n)
+}
+
+func (t *htmlText) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		t.walk(c)
+	}
+}
+
+// text 输出文本节点，合并空白
+func (t *htmlText) text(s string) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		if s != "" {
+			t.space = true
+		}
+		return
+	}
+
+	first, _ := utf8.DecodeRuneInString(s)
+	if unicode.IsSpace(first) {
+		t.space = true
+	}
+	t.write(strings.Join(fields, " "))
+	last, _ := utf8.DecodeLastRuneInString(s)
+	t.space = unicode.IsSpace(last)
+}
+
+// write 先输出待输出的换行或空格，再原样输出 s
+func (t *htmlText) write(s string) {
+	if t.sb.Len() > 0 {
+		if t.breaks > 0 {
+			t.sb.WriteString(strings.Repeat("\n", t.breaks))
+		} else if t.space {
+			t.sb.WriteByte(' ')
+		}
+	}
+	t.breaks = 0
+	t.space = false
+	t.sb.WriteString(s)
+}
+
+// lineBreak 在下一段文本之前至少换 n 行
+func (t *htmlText) lineBreak(n int) {
+	t.breaks = max(t.breaks, n)
+}
+
+func (t *htmlText) String() string {
+	return strings.TrimSpace(t.sb.String())
+}
+
+// isBoilerplate 判断元素是否为模板内容
+func isBoilerplate(n *html.Node) bool {
+	if skippedElements[n.DataAtom] {
+		return true
+	}
+	for _, attr := range n.Attr {
+		switch attr.Key {
+		case "hidden":
+			return true
+		case "aria-hidden":
+			if attr.Val == "true" {
+				return true
+			}
+		case "role":
+			if attr.Val == "navigation" || attr.Val == "banner" || attr.Val == "contentinfo" {
+				return true
+			}
+		case "class", "id":
+			for _, word := range strings.Fields(strings.ToLower(attr.Val)) {
+				if boilerplateNames[word] {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// findElement 深度优先查找第一个指定类型的元素
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// nodeText 返回节点下所有文本（不做任何处理）
+func nodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(nodeText(c))
+	}
+	return sb.String()
+}
+
+// canonicalURL 返回 <link rel="canonical"> 的链接
+func canonicalURL(root *html.Node) string {
+	var url string
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		if url != "" {
+			return
+		}
+		if n.Type == html.ElementNode && n.DataAtom == atom.Link {
+			rel, href := "", ""
+			for _, attr := range n.Attr {
+				switch attr.Key {
+				case "rel":
+					rel = strings.ToLower(attr.Val)
+				case "href":
+					href = attr.Val
+				}
+			}
+			if rel == "canonical" {
+				url = href
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			visit(c)
+		}
+	}
+	visit(root)
+	return url
+}
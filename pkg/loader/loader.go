@@ -0,0 +1,150 @@
+package loader
+
+// loader.go - 文档加载
+// 用途：从文件、目录或 glob 模式加载语料，得到可直接用于 HippoRAG.Insert / TraditionalRAG.Index 的文档
+// 主要功能：
+// - Loader 接口：把一个文件解析为一个或多个文档
+// - Load: 加载单个文件、递归加载目录或加载 glob 匹配的文件，按扩展名选择 Loader
+// - DefaultLoaders: .txt、.md、.html、.jsonl、.csv 的默认 Loader（见 text.go、html.go、records.go）
+//
+// 文档 ID 由来源名称（相对于目录的路径）生成，同一语料重复加载得到相同的 ID，
+// 可以配合 HippoRAG 的增量索引和删除使用
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/example/go-scaffold/pkg/document"
+)
+
+// Loader 文件解析器
+type Loader interface {
+	// Load 解析文件内容
+	// name: 来源名称（文件路径，目录加载时为相对路径），用于生成文档 ID 和 source 元数据
+	Load(r io.Reader, name string) ([]document.Document, error)
+}
+
+// DefaultLoaders 返回按扩展名（小写，含点）索引的默认 Loader
+func DefaultLoaders() map[string]Loader {
+	return map[string]Loader{
+		".txt":      &TextLoader{},
+		".md":       &MarkdownLoader{},
+		".markdown": &MarkdownLoader{},
+		".html":     &HTMLLoader{},
+		".htm":      &HTMLLoader{},
+		".jsonl":    &JSONLLoader{},
+		".csv":      &CSVLoader{},
+	}
+}
+
+// Load 加载文档
+// path: 文件、目录（递归加载，跳过隐藏文件和没有 Loader 的扩展名）或 glob 模式（如 "corpus/*.md"）
+// loaders: 按扩展名选择的 Loader，nil 时使用 DefaultLoaders()
+// 文档按路径排序返回
+func Load(path string, loaders map[string]Loader) ([]document.Document, error) {
+	if loaders == nil {
+		loaders = DefaultLoaders()
+	}
+
+	// glob 模式
+	if strings.ContainsAny(path, "*?[") {
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, fmt.Errorf("glob pattern: %w", err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %s", path)
+		}
+
+		var docs []document.Document
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, fmt.Errorf("stat file: %w", err)
+			}
+			if info.IsDir() {
+				continue
+			}
+			loaded, err := loadFile(match, filepath.ToSlash(match), loaders)
+			if err != nil {
+				return nil, err
+			}
+			docs = append(docs, loaded...)
+		}
+		return docs, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("stat path: %w", err)
+	}
+	if !info.IsDir() {
+		return loadFile(path, filepath.ToSlash(filepath.Clean(path)), loaders)
+	}
+
+	var docs []document.Document
+	err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if file != path && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		if _, ok := loaders[strings.ToLower(filepath.Ext(file))]; !ok {
+			return nil
+		}
+
+		rel, err := filepath.Rel(path, file)
+		if err != nil {
+			return err
+		}
+		loaded, err := loadFile(file, filepath.ToSlash(rel), loaders)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, loaded...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk dir: %w", err)
+	}
+
+	return docs, nil
+}
+
+// loadFile 按扩展名选择 Loader 解析文件
+func loadFile(path, name string, loaders map[string]Loader) ([]document.Document, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	l, ok := loaders[ext]
+	if !ok {
+		return nil, fmt.Errorf("no loader for %s files: %s", ext, path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+
+	docs, err := l.Load(f, name)
+	if err != nil {
+		return nil, fmt.Errorf("load %s: %w", path, err)
+	}
+	return docs, nil
+}
+
+// titleFromName 用不含扩展名的文件名作为默认标题
+func titleFromName(name string) string {
+	base := filepath.Base(filepath.FromSlash(name))
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
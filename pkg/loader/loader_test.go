@@ -0,0 +1,242 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/example/go-scaffold/pkg/document"
+)
+
+// TestLoaders 各格式的 Loader 解析出的文档 ID、正文和元数据
+func TestLoaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		loader  Loader
+		source  string
+		content string
+		want    []document.Document
+	}{
+		{
+			name:    "txt",
+			loader:  &TextLoader{},
+			source:  "notes/einstein.txt",
+			content: "爱因斯坦提出了相对论。\n",
+			want: []document.Document{{
+				ID:       "notes/einstein.txt",
+				Text:     "爱因斯坦提出了相对论。\n",
+				Metadata: map[string]string{"source": "notes/einstein.txt", "title": "einstein"},
+			}},
+		},
+		{
+			name:   "md front matter",
+			loader: &MarkdownLoader{},
+			source: "curie.md",
+			content: "---\nid: doc-curie\ntitle: 居里夫人\ntags: [physics, chemistry]\nyear: 1903\n" +
+				"views: 12345678\nscore: 0.5\ncreated_at: 2024-01-02T03:04:05Z\nauthor:\n  name: Marie\n---\n# 标题\n\n居里夫人发现了镭。\n",
+			want: []document.Document{{
+				ID:   "doc-curie",
+				Text: "# 标题\n\n居里夫人发现了镭。\n",
+				Metadata: map[string]string{
+					"source":     "curie.md",
+					"title":      "居里夫人",
+					"tags":       "physics, chemistry",
+					"year":       "1903",
+					"views":      "12345678",
+					"score":      "0.5",
+					"created_at": "2024-01-02T03:04:05Z",
+					"author":     `{"name":"Marie"}`,
+				},
+			}},
+		},
+		{
+			name:    "md heading title",
+			loader:  &MarkdownLoader{},
+			source:  "newton.md",
+			content: "\ufeff## 简介\n\n# 牛顿\n\n牛顿提出了万有引力定律。",
+			want: []document.Document{{
+				ID:       "newton.md",
+				Text:     "## 简介\n\n# 牛顿\n\n牛顿提出了万有引力定律。",
+				Metadata: map[string]string{"source": "newton.md", "title": "牛顿"},
+			}},
+		},
+		{
+			name:    "md without front matter end",
+			loader:  &MarkdownLoader{},
+			source:  "draft.md",
+			content: "---\n草稿",
+			want: []document.Document{{
+				ID:       "draft.md",
+				Text:     "---\n草稿",
+				Metadata: map[string]string{"source": "draft.md", "title": "draft"},
+			}},
+		},
+		{
+			name:   "html boilerplate",
+			loader: &HTMLLoader{},
+			source: "page.html",
+			content: `<html><head><title> 爱因斯坦 </title><link rel="canonical" href="https://example.com/einstein">` +
+				`<style>p{color:red}</style><script>var x = 1;</script></head><body>` +
+				`<nav><a href="/">首页</a></nav><div class="cookie-banner">接受 Cookie</div>` +
+				`<header>网站页眉</header><div id="sidebar">侧边栏</div>` +
+				`<main><header><h1>阿尔伯特·爱因斯坦</h1></header><p>他提出了<b>相对论</b>。</p>` +
+				`<ul><li>狭义相对论</li><li>广义相对论</li></ul></main>` +
+				`<footer>版权所有</footer></body></html>`,
+			want: []document.Document{{
+				ID:   "page.html",
+				Text: "# 阿尔伯特·爱因斯坦\n\n他提出了相对论。\n\n- 狭义相对论\n- 广义相对论",
+				Metadata: map[string]string{
+					"source": "page.html",
+					"title":  "爱因斯坦",
+					"url":    "https://example.com/einstein",
+				},
+			}},
+		},
+		{
+			name:   "jsonl",
+			loader: &JSONLLoader{},
+			source: "records.jsonl",
+			content: `{"id": 12345678, "text": "爱因斯坦提出了相对论。", "year": 1905, "ratio": 0.25}` + "\n\n" +
+				`{"id": 9007199254740993, "text": "居里夫人发现了镭。", "tags": ["物理", 2], "extra": {"n": 10000000}}` + "\n" +
+				`{"text": "牛顿提出了万有引力定律。", "year": null}`,
+			want: []document.Document{
+				{
+					ID:       "12345678",
+					Text:     "爱因斯坦提出了相对论。",
+					Metadata: map[string]string{"source": "records.jsonl", "year": "1905", "ratio": "0.25"},
+				},
+				{
+					ID:       "9007199254740993",
+					Text:     "居里夫人发现了镭。",
+					Metadata: map[string]string{"source": "records.jsonl", "tags": "物理, 2", "extra": `{"n":10000000}`},
+				},
+				{
+					ID:       "records.jsonl#4",
+					Text:     "牛顿提出了万有引力定律。",
+					Metadata: map[string]string{"source": "records.jsonl", "year": ""},
+				},
+			},
+		},
+		{
+			name:    "jsonl custom fields",
+			loader:  &JSONLLoader{TextField: "body", IDField: "key", MetadataFields: []string{"lang"}},
+			source:  "custom.jsonl",
+			content: `{"key": "a", "body": "镭", "lang": "zh", "ignored": 1}`,
+			want: []document.Document{{
+				ID:       "a",
+				Text:     "镭",
+				Metadata: map[string]string{"source": "custom.jsonl", "lang": "zh"},
+			}},
+		},
+		{
+			name:   "csv",
+			loader: &CSVLoader{},
+			source: "records.csv",
+			content: "\ufeffid,text,year\n" +
+				"doc-1,爱因斯坦提出了相对论。,1905\n" +
+				",\"居里夫人发现了镭，\n获得诺贝尔奖。\",1903\n",
+			want: []document.Document{
+				{
+					ID:       "doc-1",
+					Text:     "爱因斯坦提出了相对论。",
+					Metadata: map[string]string{"source": "records.csv", "year": "1905"},
+				},
+				{
+					ID:       "records.csv#3",
+					Text:     "居里夫人发现了镭，\n获得诺贝尔奖。",
+					Metadata: map[string]string{"source": "records.csv", "year": "1903"},
+				},
+			},
+		},
+		{
+			name:    "tsv custom columns",
+			loader:  &CSVLoader{TextColumn: "content", IDColumn: "key", MetadataColumns: []string{"lang"}, Comma: '\t'},
+			source:  "records.tsv",
+			content: "key\tcontent\tlang\tignored\nk1\t镭\tzh\tx\n",
+			want: []document.Document{{
+				ID:       "k1",
+				Text:     "镭",
+				Metadata: map[string]string{"source": "records.tsv", "lang": "zh"},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs, err := tt.loader.Load(strings.NewReader(tt.content), tt.source)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if !reflect.DeepEqual(docs, tt.want) {
+				t.Errorf("Load =\n%+v\nwant\n%+v", docs, tt.want)
+			}
+		})
+	}
+}
+
+// TestLoaderErrors 格式错误的输入返回错误
+func TestLoaderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		loader  Loader
+		content string
+	}{
+		{"md invalid front matter", &MarkdownLoader{}, "---\ntitle: [unclosed\n---\n正文"},
+		{"jsonl invalid json", &JSONLLoader{}, `{"text": "镭"`},
+		{"jsonl trailing data", &JSONLLoader{}, `{"text": "镭"} {"text": "钋"}`},
+		{"jsonl not an object", &JSONLLoader{}, `["镭"]`},
+		{"jsonl missing text", &JSONLLoader{}, `{"id": 1}`},
+		{"csv missing text column", &CSVLoader{}, "id,body\n1,镭\n"},
+		{"csv wrong field count", &CSVLoader{}, "id,text\n1,镭,extra\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.loader.Load(strings.NewReader(tt.content), "input"); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+// TestLoadDirectory 递归加载目录：按路径排序，ID 为相对路径，跳过隐藏文件和未知扩展名
+func TestLoadDirectory(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"b.txt":            "牛顿",
+		"a/einstein.md":    "# 爱因斯坦\n\n相对论",
+		"a/records.jsonl":  `{"id": 1, "text": "镭"}`,
+		".hidden.txt":      "隐藏",
+		"a/image.png":      "not text",
+		".git/config.txt":  "隐藏目录",
+		"c/page.html":      "<p>钋</p>",
+		"c/records.csv":    "text\n诺贝尔奖\n",
+		"c/nested/d.txt":   "万有引力",
+		"c/nested/e.jsonl": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	docs, err := Load(dir, nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	var ids []string
+	for _, doc := range docs {
+		ids = append(ids, doc.ID)
+	}
+	want := []string{"a/einstein.md", "1", "b.txt", "c/nested/d.txt", "c/page.html", "c/records.csv#2"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("ids = %q, want %q", ids, want)
+	}
+}
@@ -0,0 +1,192 @@
+package loader
+
+// records.go - JSONL 和 CSV 加载
+// 用途：每条记录（一行 JSON / 一行 CSV）作为一个文档
+// 主要功能：
+// - JSONLLoader: 可配置正文、ID 和元数据字段
+// - CSVLoader: 第一行为表头，可配置正文、ID 和元数据列
+// - 数字字段按原文保留（大整数 ID 不会变成科学计数法或丢失精度）
+// - 记录没有 ID 时使用 "<来源>#<行号>"，重复加载得到相同的 ID
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/example/go-scaffold/pkg/document"
+)
+
+// JSONLLoader JSONL 加载器（每行一个 JSON 对象）
+type JSONLLoader struct {
+	TextField string // 正文字段，为空时为 "text"
+	IDField   string // ID 字段，为空时为 "id"
+	// MetadataFields 作为元数据的字段，nil 时除正文和 ID 外的所有字段都作为元数据
+	MetadataFields []string
+}
+
+// Load 解析文件内容，空行被跳过
+func (l *JSONLLoader) Load(r io.Reader, name string) ([]document.Document, error) {
+	textField := fieldOrDefault(l.TextField, "text")
+	idField := fieldOrDefault(l.IDField, "id")
+
+	var docs []document.Document
+	reader := bufio.NewReader(r)
+	for lineNum := 1; ; lineNum++ {
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("read: %w", err)
+		}
+
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			record, err := decodeRecord(trimmed)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+
+			fields := make(map[string]string, len(record))
+			for key, value := range record {
+				fields[key] = metadataString(value)
+			}
+			doc, err := newRecordDocument(fields, textField, idField, l.MetadataFields, name, lineNum)
+			if err != nil {
+				return nil, err
+			}
+			docs = append(docs, doc)
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+
+	return docs, nil
+}
+
+// decodeRecord 解析一行 JSON 对象
+// 数字保留为 json.Number（原始文本），避免大整数 ID 经 float64 转换后变成科学计数法或丢失精度
+func decodeRecord(line string) (map[string]any, error) {
+	decoder := json.NewDecoder(strings.NewReader(line))
+	decoder.UseNumber()
+
+	var record map[string]any
+	if err := decoder.Decode(&record); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("unexpected data after JSON object")
+	}
+	return record, nil
+}
+
+// CSVLoader CSV 加载器（第一行为表头）
+type CSVLoader struct {
+	TextColumn string // 正文列，为空时为 "text"
+	IDColumn   string // ID 列，为空时为 "id"
+	// MetadataColumns 作为元数据的列，nil 时除正文和 ID 外的所有列都作为元数据
+	MetadataColumns []string
+	Comma           rune // 分隔符，为 0 时为 ','
+}
+
+// Load 解析文件内容
+func (l *CSVLoader) Load(r io.Reader, name string) ([]document.Document, error) {
+	textColumn := fieldOrDefault(l.TextColumn, "text")
+	idColumn := fieldOrDefault(l.IDColumn, "id")
+
+	reader := csv.NewReader(r)
+	if l.Comma != 0 {
+		reader.Comma = l.Comma
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	if !containsString(header, textColumn) {
+		return nil, fmt.Errorf("missing text column %q", textColumn)
+	}
+
+	var docs []document.Document
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read row: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		fields := make(map[string]string, len(header))
+		for i, column := range header {
+			if i < len(row) {
+				fields[column] = row[i]
+			}
+		}
+		doc, err := newRecordDocument(fields, textColumn, idColumn, l.MetadataColumns, name, line)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
+
+// newRecordDocument 由一条记录的字段构造文档
+func newRecordDocument(fields map[string]string, textField, idField string, metadataFields []string, name string, line int) (document.Document, error) {
+	text, ok := fields[textField]
+	if !ok {
+		return document.Document{}, fmt.Errorf("line %d: missing text field %q", line, textField)
+	}
+
+	id := fields[idField]
+	if id == "" {
+		id = name + "#" + strconv.Itoa(line)
+	}
+
+	metadata := map[string]string{document.MetaSource: name}
+	if metadataFields == nil {
+		for key, value := range fields {
+			if key != textField && key != idField {
+				metadata[key] = value
+			}
+		}
+	} else {
+		for _, key := range metadataFields {
+			if value, ok := fields[key]; ok {
+				metadata[key] = value
+			}
+		}
+	}
+
+	return document.Document{ID: id, Text: text, Metadata: metadata}, nil
+}
+
+// fieldOrDefault 字段名为空时返回默认值
+func fieldOrDefault(field, defaultField string) string {
+	if field == "" {
+		return defaultField
+	}
+	return field
+}
+
+// containsString 判断切片中是否包含 s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package loader
+
+// text.go - 纯文本和 Markdown 加载
+// 用途：每个文件作为一个文档
+// 主要功能：
+// - TextLoader: 整个文件作为正文，文件名作为标题
+// - MarkdownLoader: 解析 YAML front matter（--- 包围的头部）作为元数据，
+//   标题依次取 front matter 的 title、第一个一级标题、文件名；正文保留 Markdown 格式（便于按标题分块）
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/example/go-scaffold/pkg/document"
+)
+
+// TextLoader 纯文本加载器
+type TextLoader struct{}
+
+// Load 解析文件内容
+func (l *TextLoader) Load(r io.Reader, name string) ([]document.Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read: %w", err)
+	}
+
+	return []document.Document{{
+		ID:   name,
+		Text: string(data),
+		Metadata: map[string]string{
+			document.MetaSource: name,
+			document.MetaTitle:  titleFromName(name),
+		},
+	}}, nil
+}
+
+// MarkdownLoader Markdown 加载器
+type MarkdownLoader struct{}
+
+// Load 解析文件内容
+// front matter 中的 id 字段作为文档 ID，其余字段作为元数据（列表以 ", " 连接，嵌套结构序列化为 JSON，时间为 RFC 3339）
+func (l *MarkdownLoader) Load(r io.Reader, name string) ([]document.Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read: %w", err)
+	}
+
+	frontMatter, body := splitFrontMatter(string(data))
+
+	metadata := map[string]string{document.MetaSource: name}
+	id := name
+	if frontMatter != "" {
+		var fields map[string]any
+		if err := yaml.Unmarshal([]byte(frontMatter), &fields); err != nil {
+			return nil, fmt.Errorf("parse front matter: %w", err)
+		}
+		for key, value := range fields {
+			metadata[key] = metadataString(value)
+		}
+		if value, ok := metadata["id"]; ok {
+			if value != "" {
+				id = value
+			}
+			delete(metadata, "id")
+		}
+	}
+
+	if metadata[document.MetaTitle] == "" {
+		metadata[document.MetaTitle] = firstHeading(body)
+	}
+	if metadata[document.MetaTitle] == "" {
+		metadata[document.MetaTitle] = titleFromName(name)
+	}
+
+	return []document.Document{{ID: id, Text: body, Metadata: metadata}}, nil
+}
+
+// splitFrontMatter 拆分 YAML front matter 和正文，没有 front matter 时返回空串和原文
+func splitFrontMatter(text string) (string, string) {
+	text = strings.TrimPrefix(text, "\ufeff")
+	normalized := strings.ReplaceAll(text, "\r\n", "\n")
+	if !strings.HasPrefix(normalized, "---\n") {
+		return "", text
+	}
+
+	rest := normalized[len("---\n"):]
+	for offset := 0; offset < len(rest); {
+		end := strings.IndexByte(rest[offset:], '\n')
+		line := rest[offset:]
+		if end >= 0 {
+			line = rest[offset : offset+end]
+		}
+		if strings.TrimRight(line, " \t") == "---" || strings.TrimRight(line, " \t") == "..." {
+			body := ""
+			if end >= 0 {
+				body = rest[offset+end+1:]
+			}
+			return rest[:offset], body
+		}
+		if end < 0 {
+			break
+		}
+		offset += end + 1
+	}
+
+	// 没有结束标记，不是 front matter
+	return "", text
+}
+
+// firstHeading 返回第一个一级标题（"# 标题"）的文本
+func firstHeading(body string) string {
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "# ") {
+			return strings.TrimSpace(line[2:])
+		}
+	}
+	return ""
+}
+
+// metadataString 把 front matter / JSON 字段值转换为元数据字符串
+func metadataString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		// 不使用 fmt.Sprint，避免 12345678 这样的整数被格式化为 1.2345678e+07
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = metadataString(item)
+		}
+		return strings.Join(parts, ", ")
+	case map[string]any:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,179 @@
+package openie
+
+// batch.go - 批量并发抽取
+// 用途：用有界的 worker pool 并发调用 LLM，对大量文本块执行 OpenIE
+// 主要功能：
+// - ExtractBatch: 并发提取，结果按输入顺序返回
+// - SetConcurrency: 设置并发数
+// - SetErrorPolicy: 设置单个文本块失败时的处理策略（立即失败 / 跳过并报告 / 重试）
+// - SetRetryBackoff: 设置重试之间的退避（指数退避 + 抖动，遵守限流错误的 Retry-After）
+// - 失败的文本块通过 BatchResult.Failed 返回给调用方
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/example/go-scaffold/pkg/retry"
+)
+
+// ErrorPolicy 单个文本块提取失败时的处理策略
+type ErrorPolicy string
+
+const (
+	// ErrorPolicyFailFast 遇到第一个错误时取消其余任务并返回错误
+	ErrorPolicyFailFast ErrorPolicy = "fail_fast"
+	// ErrorPolicySkip 跳过失败的文本块，在 BatchResult.Failed 中报告
+	ErrorPolicySkip ErrorPolicy = "skip"
+	// ErrorPolicyRetry 重试失败的文本块，重试次数用完后跳过并报告
+	ErrorPolicyRetry ErrorPolicy = "retry"
+)
+
+// FailedChunk 提取失败的文本块
+type FailedChunk struct {
+	Index    int    // 在输入中的下标
+	Text     string // 文本内容
+	Attempts int    // 尝试次数
+	Err      error  // 最后一次的错误
+}
+
+// BatchResult 批量提取结果
+type BatchResult struct {
+	// Results 与输入一一对应，失败的文本块对应 nil
+	Results []*ExtractionResult
+	// Failed 失败的文本块（按下标排序），ErrorPolicyFailFast 时总为空
+	Failed []FailedChunk
+}
+
+// SetConcurrency 设置批量提取的并发数（<= 0 时按 1 处理）
+func (e *Extractor) SetConcurrency(n int) {
+	if n <= 0 {
+		n = 1
+	}
+	e.concurrency = n
+}
+
+// SetErrorPolicy 设置失败处理策略
+// maxRetries: ErrorPolicyRetry 时每个文本块的最大重试次数（不含第一次尝试）
+func (e *Extractor) SetErrorPolicy(policy ErrorPolicy, maxRetries int) error {
+	switch policy {
+	case ErrorPolicyFailFast, ErrorPolicySkip, ErrorPolicyRetry:
+	default:
+		return fmt.Errorf("unknown error policy: %q", policy)
+	}
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+
+	e.errorPolicy = policy
+	e.maxRetries = maxRetries
+	return nil
+}
+
+// SetRetryBackoff 设置 ErrorPolicyRetry 时两次尝试之间的退避（默认 retry.DefaultPolicy）
+// 只使用退避参数（BaseDelay、MaxDelay、Multiplier、Jitter），重试次数由 SetErrorPolicy 设置
+func (e *Extractor) SetRetryBackoff(policy retry.Policy) {
+	e.backoff = policy
+}
+
+// ExtractBatch 批量提取（并发处理，结果保持输入顺序）
+// ErrorPolicyFailFast 时任一文本块失败即返回错误；其余策略下失败的文本块记录在 BatchResult.Failed 中
+// ctx 被取消时返回 ctx 的错误
+func (e *Extractor) ExtractBatch(ctx context.Context, texts []string) (*BatchResult, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]*ExtractionResult, len(texts))
+	failures := make([]*FailedChunk, len(texts))
+
+	var (
+		firstErr error
+		errOnce  sync.Once
+	)
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	workers := e.concurrency
+	if workers > len(texts) {
+		workers = len(texts)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				result, attempts, err := e.extractWithPolicy(ctx, texts[i])
+				if err == nil {
+					results[i] = result
+					continue
+				}
+
+				if e.errorPolicy == ErrorPolicyFailFast {
+					errOnce.Do(func() {
+						firstErr = fmt.Errorf("extract text %d: %w", i, err)
+						cancel()
+					})
+					continue
+				}
+				failures[i] = &FailedChunk{Index: i, Text: texts[i], Attempts: attempts, Err: err}
+			}
+		}()
+	}
+
+dispatch:
+	for i := range texts {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	batch := &BatchResult{Results: results}
+	for _, failure := range failures {
+		if failure != nil {
+			batch.Failed = append(batch.Failed, *failure)
+		}
+	}
+	return batch, nil
+}
+
+// extractWithPolicy 提取单个文本块，ErrorPolicyRetry 时失败后退避并重试
+// 退避期间 ctx 被取消时立即返回
+// 返回：提取结果、尝试次数、最后一次的错误
+func (e *Extractor) extractWithPolicy(ctx context.Context, text string) (*ExtractionResult, int, error) {
+	attempts := 1
+	if e.errorPolicy == ErrorPolicyRetry {
+		attempts += e.maxRetries
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		result, err := e.Extract(ctx, text)
+		if err == nil {
+			return result, attempt, nil
+		}
+		lastErr = err
+
+		// 已取消时不再重试
+		if ctx.Err() != nil {
+			return nil, attempt, lastErr
+		}
+		if attempt < attempts {
+			if err := e.backoff.Wait(ctx, attempt, lastErr); err != nil {
+				return nil, attempt, lastErr
+			}
+		}
+	}
+
+	return nil, attempts, lastErr
+}
@@ -0,0 +1,250 @@
+package openie
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/example/go-scaffold/pkg/retry"
+)
+
+// chunkPattern 测试文本块中的编号
+var chunkPattern = regexp.MustCompile(`chunk-\d+`)
+
+// batchClient 按文本块编号返回结果的 LLM 客户端
+// failures: 编号 -> 失败次数（-1 表示总是失败）
+func batchClient(failures map[string]int) *fakeClient {
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	return &fakeClient{
+		complete: func(prompt string) (string, error) {
+			name := chunkPattern.FindString(prompt)
+			time.Sleep(time.Duration(rand.Intn(3)) * time.Millisecond) // 打乱完成顺序
+
+			mu.Lock()
+			calls[name]++
+			n := calls[name]
+			mu.Unlock()
+
+			if limit, ok := failures[name]; ok && (limit < 0 || n <= limit) {
+				return "", fmt.Errorf("llm unavailable for %s", name)
+			}
+			return fmt.Sprintf(`{"entities": [%q], "triples": []}`, name), nil
+		},
+	}
+}
+
+func batchTexts(n int) []string {
+	texts := make([]string, n)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("chunk-%02d", i)
+	}
+	return texts
+}
+
+// fastBackoff 测试用的短退避
+func fastBackoff() retry.Policy {
+	return retry.Policy{BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+}
+
+// TestExtractBatchOrder 并发提取的结果按输入顺序返回
+func TestExtractBatchOrder(t *testing.T) {
+	texts := batchTexts(30)
+	extractor := NewExtractor(batchClient(nil))
+	extractor.SetConcurrency(8)
+
+	batch, err := extractor.ExtractBatch(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("ExtractBatch: %v", err)
+	}
+	if len(batch.Results) != len(texts) || len(batch.Failed) != 0 {
+		t.Fatalf("got %d results, %d failed", len(batch.Results), len(batch.Failed))
+	}
+	for i, result := range batch.Results {
+		if result == nil || len(result.Entities) != 1 || result.Entities[0] != texts[i] {
+			t.Errorf("result %d = %+v, want entity %s", i, result, texts[i])
+		}
+	}
+}
+
+// TestExtractBatchPolicies 三种失败策略下的返回值和 Failed 列表
+func TestExtractBatchPolicies(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     ErrorPolicy
+		maxRetries int
+		failures   map[string]int
+		wantErr    bool
+		wantFailed map[int]int // 下标 -> 尝试次数
+	}{
+		{
+			name:     "fail fast",
+			policy:   ErrorPolicyFailFast,
+			failures: map[string]int{"chunk-03": -1},
+			wantErr:  true,
+		},
+		{
+			name:       "skip",
+			policy:     ErrorPolicySkip,
+			failures:   map[string]int{"chunk-02": -1, "chunk-05": 1},
+			wantFailed: map[int]int{2: 1, 5: 1},
+		},
+		{
+			name:       "retry",
+			policy:     ErrorPolicyRetry,
+			maxRetries: 2,
+			failures:   map[string]int{"chunk-01": 2, "chunk-04": -1, "chunk-06": 3},
+			wantFailed: map[int]int{4: 3, 6: 3},
+		},
+		{
+			name:       "retry without retries",
+			policy:     ErrorPolicyRetry,
+			failures:   map[string]int{"chunk-01": 1},
+			wantFailed: map[int]int{1: 1},
+		},
+		{
+			name:     "no failures",
+			policy:   ErrorPolicyFailFast,
+			failures: map[string]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			texts := batchTexts(8)
+			extractor := NewExtractor(batchClient(tt.failures))
+			extractor.SetConcurrency(3)
+			extractor.SetRetryBackoff(fastBackoff())
+			if err := extractor.SetErrorPolicy(tt.policy, tt.maxRetries); err != nil {
+				t.Fatalf("SetErrorPolicy: %v", err)
+			}
+
+			batch, err := extractor.ExtractBatch(context.Background(), texts)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", batch)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExtractBatch: %v", err)
+			}
+
+			if len(batch.Failed) != len(tt.wantFailed) {
+				t.Fatalf("failed = %+v, want indexes %v", batch.Failed, tt.wantFailed)
+			}
+			for j, failure := range batch.Failed {
+				if j > 0 && failure.Index <= batch.Failed[j-1].Index {
+					t.Errorf("failed chunks not sorted by index: %+v", batch.Failed)
+				}
+				attempts, ok := tt.wantFailed[failure.Index]
+				if !ok || failure.Attempts != attempts || failure.Text != texts[failure.Index] || failure.Err == nil {
+					t.Errorf("failure %+v, want %d attempts", failure, attempts)
+				}
+			}
+			for i, result := range batch.Results {
+				_, failed := tt.wantFailed[i]
+				if failed != (result == nil) {
+					t.Errorf("result %d = %+v, failed %v", i, result, failed)
+				}
+				if result != nil && result.Entities[0] != texts[i] {
+					t.Errorf("result %d has entity %s", i, result.Entities[0])
+				}
+			}
+		})
+	}
+}
+
+// TestSetErrorPolicyUnknown 未知的策略返回错误
+func TestSetErrorPolicyUnknown(t *testing.T) {
+	if err := NewExtractor(&fakeClient{}).SetErrorPolicy("ignore", 1); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+// TestExtractBatchRetryBackoff 重试之间按退避策略等待
+func TestExtractBatchRetryBackoff(t *testing.T) {
+	extractor := NewExtractor(batchClient(map[string]int{"chunk-00": -1}))
+	extractor.SetRetryBackoff(retry.Policy{BaseDelay: 20 * time.Millisecond, Multiplier: 2})
+	if err := extractor.SetErrorPolicy(ErrorPolicyRetry, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	batch, err := extractor.ExtractBatch(context.Background(), batchTexts(1))
+	if err != nil {
+		t.Fatalf("ExtractBatch: %v", err)
+	}
+	// 20ms + 40ms
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("retries finished after %v, want at least 60ms of backoff", elapsed)
+	}
+	if len(batch.Failed) != 1 || batch.Failed[0].Attempts != 3 {
+		t.Errorf("failed = %+v", batch.Failed)
+	}
+}
+
+// TestExtractBatchRetryAfter 限流错误的 Retry-After 优先于退避策略
+func TestExtractBatchRetryAfter(t *testing.T) {
+	calls := 0
+	client := &fakeClient{
+		complete: func(string) (string, error) {
+			calls++
+			if calls == 1 {
+				return "", fmt.Errorf("llm complete: %w", &retry.APIError{StatusCode: 429, RetryAfter: 30 * time.Millisecond})
+			}
+			return `{"entities": [], "triples": []}`, nil
+		},
+	}
+	extractor := NewExtractor(client)
+	extractor.SetRetryBackoff(retry.Policy{BaseDelay: time.Hour})
+	if err := extractor.SetErrorPolicy(ErrorPolicyRetry, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	batch, err := extractor.ExtractBatch(ctx, batchTexts(1))
+	if err != nil {
+		t.Fatalf("ExtractBatch: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("retried after %v, want the 30ms Retry-After", elapsed)
+	}
+	if batch.Results[0] == nil {
+		t.Errorf("retry did not succeed: %+v", batch.Failed)
+	}
+}
+
+// TestExtractBatchCancel 退避等待期间取消 ctx 时立即返回 ctx 的错误
+func TestExtractBatchCancel(t *testing.T) {
+	extractor := NewExtractor(batchClient(map[string]int{"chunk-00": -1, "chunk-01": -1}))
+	extractor.SetConcurrency(2)
+	extractor.SetRetryBackoff(retry.Policy{BaseDelay: time.Hour})
+	if err := extractor.SetErrorPolicy(ErrorPolicyRetry, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(30*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := extractor.ExtractBatch(ctx, batchTexts(4))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("returned after %v", elapsed)
+	}
+
+	// 已取消的 ctx
+	if _, err := NewExtractor(batchClient(nil)).ExtractBatch(ctx, batchTexts(3)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,134 @@
+package openie
+
+// cache.go - OpenIE 抽取结果缓存
+// 用途：把每个文本块的抽取结果保存到本地目录，重新索引时只对新的或修改过的文本块调用 LLM
+// 主要功能：
+// - Cache: 按 (版本, 文本内容哈希) 存取 ExtractionResult
+// - CacheVersion: 由模型名、抽取模式和提示词版本 ID 生成版本号，任一变化后自动使用新的缓存目录
+// - 每个文本块一个格式化的 JSON 文件（<dir>/<version>/<hash>.json），可以直接查看和手工修正
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/example/go-scaffold/pkg/prompt"
+	"github.com/example/go-scaffold/pkg/utils"
+)
+
+// cachedExtraction 缓存文件内容（保存原文便于人工核对）
+type cachedExtraction struct {
+	Text string `json:"text"`
+	ExtractionResult
+}
+
+// Cache OpenIE 抽取结果缓存（并发安全）
+type Cache struct {
+	dir    string // <根目录>/<版本>
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NewCache 创建抽取结果缓存
+// dir: 缓存根目录；version: 模型 / 提示词版本（见 CacheVersion），不同版本的结果互不影响
+func NewCache(dir, version string) *Cache {
+	return &Cache{dir: filepath.Join(dir, sanitizePath(version))}
+}
+
+// CacheVersion 返回缓存版本号：模型名 + 抽取模式 + 所用提示词版本 ID 的哈希
+// llm 客户端实现了 Model() string 时使用其模型名
+func (e *Extractor) CacheVersion() string {
+	model := "unknown"
+	if namer, ok := e.llmClient.(interface{ Model() string }); ok && namer.Model() != "" {
+		model = namer.Model()
+	}
+
+	names := []string{prompt.OpenIEExtract}
+	if e.mode == ModeTwoStage {
+		names = []string{prompt.OpenIENER, prompt.OpenIETriples}
+	}
+	ids := make([]string, 0, len(names))
+	for _, name := range names {
+		if t, err := e.prompts.Get(name); err == nil {
+			ids = append(ids, t.ID())
+		}
+	}
+
+	return model + "-" + string(e.mode) + "-" + utils.Hash(strings.Join(ids, ","))[:8]
+}
+
+// sanitizePath 把版本号中不适合作为目录名的字符替换为 "_"
+func sanitizePath(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// path 返回文本对应的缓存文件路径
+func (c *Cache) path(text string) string {
+	return filepath.Join(c.dir, utils.Hash(text)+".json")
+}
+
+// Get 读取文本的抽取结果
+// 文件不存在或无法解析时视为未命中
+func (c *Cache) Get(text string) (*ExtractionResult, bool) {
+	data, err := os.ReadFile(c.path(text))
+	if err != nil {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	var entry cachedExtraction
+	if err := json.Unmarshal(data, &entry); err != nil {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.hits.Add(1)
+	result := entry.ExtractionResult
+	return &result, true
+}
+
+// Put 保存文本的抽取结果（先写临时文件再重命名，避免留下不完整的文件）
+func (c *Cache) Put(text string, result *ExtractionResult) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("create cache dir: %w", err)
+	}
+
+	data, err := json.MarshalIndent(cachedExtraction{Text: text, ExtractionResult: *result}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal data: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("close file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(text)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("rename file: %w", err)
+	}
+
+	return nil
+}
+
+// Stats 返回命中和未命中次数
+func (c *Cache) Stats() (hits, misses int64) {
+	return c.hits.Load(), c.misses.Load()
+}
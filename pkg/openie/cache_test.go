@@ -0,0 +1,166 @@
+package openie
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/example/go-scaffold/pkg/prompt"
+)
+
+// namedClient 带模型名的 fakeClient
+type namedClient struct {
+	*fakeClient
+	model string
+}
+
+func (c *namedClient) Model() string {
+	return c.model
+}
+
+// TestCacheRoundTrip Put 之后 Get 得到相同结果，文件中保存原文
+func TestCacheRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewCache(dir, "gpt/4o:v1")
+
+	result := &ExtractionResult{
+		Entities: []string{"居里夫人", "镭"},
+		Triples:  []Triple{{"居里夫人", "发现了", "镭"}},
+	}
+	if err := cache.Put("居里夫人发现了镭。", result); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	got, ok := cache.Get("居里夫人发现了镭。")
+	if !ok || !reflect.DeepEqual(got, result) {
+		t.Errorf("Get = %+v, %v, want %+v", got, ok, result)
+	}
+	if _, ok := cache.Get("爱因斯坦出生于乌尔姆。"); ok {
+		t.Error("unexpected hit for another text")
+	}
+	if hits, misses := cache.Stats(); hits != 1 || misses != 1 {
+		t.Errorf("stats = %d hits, %d misses, want 1, 1", hits, misses)
+	}
+
+	// 版本号中的 "/" ":" 不会产生子目录
+	files, err := filepath.Glob(filepath.Join(dir, "gpt_4o_v1", "*.json"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("cache files = %v, %v", files, err)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"text": "居里夫人发现了镭。"`) {
+		t.Errorf("cache file does not contain the text:\n%s", data)
+	}
+}
+
+// TestCacheCorruptEntry 无法解析的缓存文件视为未命中，重新 Put 后恢复
+func TestCacheCorruptEntry(t *testing.T) {
+	cache := NewCache(t.TempDir(), "v1")
+	text := "居里夫人发现了镭。"
+	result := &ExtractionResult{Entities: []string{"居里夫人"}, Triples: []Triple{}}
+	if err := cache.Put(text, result); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	if err := os.WriteFile(cache.path(text), []byte(`{"text": "居里夫人`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Get(text); ok {
+		t.Fatal("unexpected hit for corrupt entry")
+	}
+
+	if err := cache.Put(text, result); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if got, ok := cache.Get(text); !ok || !reflect.DeepEqual(got, result) {
+		t.Errorf("Get = %+v, %v after rewrite", got, ok)
+	}
+}
+
+// TestCacheVersion 模型名、抽取模式或提示词变化时版本号变化
+func TestCacheVersion(t *testing.T) {
+	newExtractor := func(model string) *Extractor {
+		return NewExtractor(&namedClient{fakeClient: &fakeClient{}, model: model})
+	}
+
+	base := newExtractor("gpt-4o").CacheVersion()
+	if base != newExtractor("gpt-4o").CacheVersion() {
+		t.Error("version is not stable")
+	}
+	if !strings.HasPrefix(base, "gpt-4o-single-") {
+		t.Errorf("version = %q", base)
+	}
+	if newExtractor("gpt-4o-mini").CacheVersion() == base {
+		t.Error("version does not change with the model")
+	}
+	if NewExtractor(&fakeClient{}).CacheVersion() == base {
+		t.Error("version does not change without a model name")
+	}
+
+	twoStage := newExtractor("gpt-4o")
+	if err := twoStage.SetMode(ModeTwoStage); err != nil {
+		t.Fatal(err)
+	}
+	if twoStage.CacheVersion() == base {
+		t.Error("version does not change with the mode")
+	}
+
+	prompts, err := prompt.NewRegistry("en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := prompts.Set(prompt.OpenIEExtract, "Extract from: {{.Text}}"); err != nil {
+		t.Fatal(err)
+	}
+	custom := newExtractor("gpt-4o")
+	custom.SetPrompts(prompts)
+	if custom.CacheVersion() == base {
+		t.Error("version does not change with the prompt")
+	}
+}
+
+// TestExtractorCache 命中缓存时不调用 LLM，模型变化后重新抽取
+func TestExtractorCache(t *testing.T) {
+	dir := t.TempDir()
+	text := "Marie Curie discovered radium."
+	newExtractor := func(model string) (*Extractor, *fakeClient) {
+		client := &fakeClient{complete: func(string) (string, error) {
+			return `{"entities": ["Marie Curie", "radium"], "triples": [["Marie Curie", "discovered", "radium"]]}`, nil
+		}}
+		extractor := NewExtractor(&namedClient{fakeClient: client, model: model})
+		extractor.SetCache(NewCache(dir, extractor.CacheVersion()))
+		return extractor, client
+	}
+
+	first, client := newExtractor("gpt-4o")
+	want, err := first.Extract(context.Background(), text)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if client.completeCalls != 1 {
+		t.Fatalf("complete calls = %d, want 1", client.completeCalls)
+	}
+
+	second, client := newExtractor("gpt-4o")
+	got, err := second.Extract(context.Background(), text)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if client.completeCalls != 0 || !reflect.DeepEqual(got, want) {
+		t.Errorf("cached extract = %+v with %d calls, want %+v without calls", got, client.completeCalls, want)
+	}
+
+	other, client := newExtractor("gpt-4o-mini")
+	if _, err := other.Extract(context.Background(), text); err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if client.completeCalls != 1 {
+		t.Errorf("complete calls = %d after model change, want 1", client.completeCalls)
+	}
+}
@@ -4,30 +4,52 @@ package openie
 // 用途：从文本中提取实体和关系三元组 (主语, 谓语, 宾语)
 // 主要功能：
 // - Extract: 使用 LLM 从文本中提取结构化的实体关系
-// - 支持批量处理
+// - 支持批量并发处理（见 batch.go）
+// - 容错解析 LLM 输出，无法解析时带着错误重新请求（见 parse.go）
+// - 可选的抽取结果缓存（见 cache.go）
+// - 两种抽取模式：一次性抽取实体和三元组（默认），或先 NER 再抽取三元组（见 two_stage.go）
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"strings"
-)
 
-// LLMClient LLM 客户端接口（用于调用 OpenAI 等）
-type LLMClient interface {
-	// Complete 生成文本补全
-	Complete(ctx context.Context, prompt string) (string, error)
-}
+	"github.com/example/go-scaffold/pkg/llm"
+	"github.com/example/go-scaffold/pkg/prompt"
+	"github.com/example/go-scaffold/pkg/retry"
+)
 
 // Extractor OpenIE 提取器
 type Extractor struct {
-	llmClient LLMClient
+	llmClient llm.Client
+
+	// 批量提取参数（见 batch.go）
+	concurrency int          // 并发数
+	errorPolicy ErrorPolicy  // 单个文本块失败时的处理策略
+	maxRetries  int          // ErrorPolicyRetry 时的最大重试次数
+	backoff     retry.Policy // ErrorPolicyRetry 时两次尝试之间的退避
+
+	maxRepairs int // 输出无法解析时重新请求 LLM 的最大次数（见 parse.go）
+
+	prompts *prompt.Registry // 提示词模板
+
+	cache *Cache // 抽取结果缓存（见 cache.go），nil 表示不缓存
+
+	mode  ExtractionMode  // 抽取模式
+	stats validationStats // 两阶段模式下的三元组校验统计
 }
 
 // NewExtractor 创建 OpenIE 提取器
-func NewExtractor(llmClient LLMClient) *Extractor {
+// 默认逐个处理，遇到错误立即失败（retry 策略下按 retry.DefaultPolicy 退避），输出无法解析时最多重新请求 2 次，
+// 使用默认语言的内置提示词
+func NewExtractor(llmClient llm.Client) *Extractor {
 	return &Extractor{
-		llmClient: llmClient,
+		llmClient:   llmClient,
+		concurrency: 1,
+		errorPolicy: ErrorPolicyFailFast,
+		backoff:     retry.DefaultPolicy(),
+		maxRepairs:  2,
+		prompts:     prompt.Default(),
+		mode:        ModeSingle,
 	}
 }
 
@@ -44,66 +66,72 @@ type ExtractionResult struct {
 	Triples  []Triple `json:"triples"`  // 关系三元组
 }
 
+// SetPrompts 设置提示词模板（设置缓存前调用，缓存版本号依赖提示词版本）
+func (e *Extractor) SetPrompts(prompts *prompt.Registry) {
+	e.prompts = prompts
+}
+
+// SetCache 设置抽取结果缓存，nil 表示不缓存
+func (e *Extractor) SetCache(cache *Cache) {
+	e.cache = cache
+}
+
 // Extract 从文本中提取实体和关系
+// 设置了缓存时优先使用缓存结果，新的结果写入缓存
 func (e *Extractor) Extract(ctx context.Context, text string) (*ExtractionResult, error) {
-	// 构造提示词
-	prompt := buildExtractionPrompt(text)
+	if e.cache != nil {
+		if result, ok := e.cache.Get(text); ok {
+			return result, nil
+		}
+	}
 
-	// 调用 LLM
-	response, err := e.llmClient.Complete(ctx, prompt)
+	var result *ExtractionResult
+	var err error
+	if e.mode == ModeTwoStage {
+		result, err = e.extractTwoStage(ctx, text)
+	} else {
+		result, err = e.extractSingle(ctx, text)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("llm complete: %w", err)
+		return nil, err
 	}
 
-	// 解析 JSON 响应
-	result, err := parseExtractionResponse(response)
-	if err != nil {
-		return nil, fmt.Errorf("parse response: %w", err)
+	if e.cache != nil {
+		if err := e.cache.Put(text, result); err != nil {
+			return nil, fmt.Errorf("cache result: %w", err)
+		}
 	}
 
 	return result, nil
 }
 
-// buildExtractionPrompt 构造 OpenIE 提示词
-func buildExtractionPrompt(text string) string {
-	return fmt.Sprintf(`Extract entities and relationships from the following text.
-Return the result in JSON format with two fields:
-1. "entities": a list of all entities (nouns, proper nouns)
-2. "triples": a list of relationship triples, each with "subject", "predicate", "object"
-
-Text: %s
-
-Return only valid JSON, no additional text.`, text)
-}
+// extractSingle 一次性抽取实体和三元组
+func (e *Extractor) extractSingle(ctx context.Context, text string) (*ExtractionResult, error) {
+	p, err := e.prompts.Render(prompt.OpenIEExtract, map[string]any{"Text": text})
+	if err != nil {
+		return nil, err
+	}
 
-// parseExtractionResponse 解析 LLM 返回的 JSON
-func parseExtractionResponse(response string) (*ExtractionResult, error) {
-	// 清理响应（移除可能的 markdown 代码块标记）
-	response = strings.TrimSpace(response)
-	response = strings.TrimPrefix(response, "```json")
-	response = strings.TrimPrefix(response, "```")
-	response = strings.TrimSuffix(response, "```")
-	response = strings.TrimSpace(response)
-
-	var result ExtractionResult
-	if err := json.Unmarshal([]byte(response), &result); err != nil {
-		return nil, fmt.Errorf("unmarshal json: %w", err)
+	var result *ExtractionResult
+	err = e.completeJSON(ctx, p, func(response string) error {
+		var err error
+		result, err = parseExtractionResponse(response)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return &result, nil
+	return result, nil
 }
 
-// ExtractBatch 批量提取（逐个处理）
-func (e *Extractor) ExtractBatch(ctx context.Context, texts []string) ([]*ExtractionResult, error) {
-	results := make([]*ExtractionResult, len(texts))
-
-	for i, text := range texts {
-		result, err := e.Extract(ctx, text)
-		if err != nil {
-			return nil, fmt.Errorf("extract text %d: %w", i, err)
-		}
-		results[i] = result
+// parseExtractionResponse 解析 LLM 返回的 JSON（容错规则见 parse.go），结构不对的三元组被跳过
+func parseExtractionResponse(response string) (*ExtractionResult, error) {
+	raw, err := decodeExtraction(response)
+	if err != nil {
+		return nil, err
 	}
 
-	return results, nil
+	triples, _ := raw.triples()
+	return &ExtractionResult{Entities: raw.entities(), Triples: triples}, nil
 }
@@ -0,0 +1,72 @@
+package openie
+
+// fallback.go - 回退抽取器
+// 用途：主抽取器（通常是 LLM）失败时改用备用抽取器（通常是规则抽取器），避免文本块没有任何抽取结果
+// 主要功能：
+// - Extract: 主抽取器失败时调用备用抽取器
+// - ExtractBatch: 主抽取器报告失败的文本块逐个交给备用抽取器；整批失败时整批回退
+
+import (
+	"context"
+	"fmt"
+)
+
+// FallbackExtractor 带回退的抽取器
+type FallbackExtractor struct {
+	primary  TextExtractor
+	fallback TextExtractor
+}
+
+// NewFallbackExtractor 创建回退抽取器
+// 建议主抽取器使用 ErrorPolicySkip 或 ErrorPolicyRetry，这样只有失败的文本块会回退
+func NewFallbackExtractor(primary, fallback TextExtractor) *FallbackExtractor {
+	return &FallbackExtractor{
+		primary:  primary,
+		fallback: fallback,
+	}
+}
+
+// Extract 从文本中提取实体和关系，主抽取器失败时使用备用抽取器
+func (e *FallbackExtractor) Extract(ctx context.Context, text string) (*ExtractionResult, error) {
+	result, err := e.primary.Extract(ctx, text)
+	if err == nil {
+		return result, nil
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	result, fallbackErr := e.fallback.Extract(ctx, text)
+	if fallbackErr != nil {
+		return nil, fmt.Errorf("primary: %v; fallback: %w", err, fallbackErr)
+	}
+	return result, nil
+}
+
+// ExtractBatch 批量提取
+// 主抽取器失败的文本块改用备用抽取器，两者都失败的文本块保留在 Failed 中
+func (e *FallbackExtractor) ExtractBatch(ctx context.Context, texts []string) (*BatchResult, error) {
+	batch, err := e.primary.ExtractBatch(ctx, texts)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		// 整批失败（如 fail_fast 策略），整批回退
+		fmt.Printf("  Primary extractor failed (%v), falling back for %d chunks\n", err, len(texts))
+		return e.fallback.ExtractBatch(ctx, texts)
+	}
+
+	var failed []FailedChunk
+	for _, chunk := range batch.Failed {
+		result, err := e.fallback.Extract(ctx, chunk.Text)
+		if err != nil {
+			chunk.Err = fmt.Errorf("primary: %v; fallback: %w", chunk.Err, err)
+			failed = append(failed, chunk)
+			continue
+		}
+		batch.Results[chunk.Index] = result
+	}
+	batch.Failed = failed
+
+	return batch, nil
+}
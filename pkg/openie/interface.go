@@ -0,0 +1,22 @@
+package openie
+
+// interface.go - 抽取器接口定义
+// 用途：定义统一的 OpenIE 接口，支持不同的实现（LLM、规则/词典、组合回退等）
+// 主要功能：
+// - TextExtractor 接口：单个文本抽取和批量抽取
+
+import "context"
+
+// TextExtractor 抽取器接口
+// 可以有多种实现：
+// - Extractor: 基于 LLM（效果好，成本高）
+// - RuleExtractor: 基于词典和规则（无需 LLM，作为基线或回退）
+// - FallbackExtractor: 主抽取器失败时使用备用抽取器
+type TextExtractor interface {
+	// Extract 从文本中提取实体和关系
+	Extract(ctx context.Context, text string) (*ExtractionResult, error)
+
+	// ExtractBatch 批量提取，结果与输入一一对应
+	// 失败的文本块对应的结果为 nil，并记录在 BatchResult.Failed 中
+	ExtractBatch(ctx context.Context, texts []string) (*BatchResult, error)
+}
@@ -0,0 +1,309 @@
+package openie
+
+// parse.go - 容错的 LLM 输出解析
+// 用途：LLM 返回的 JSON 常带有多余的说明文字、单引号、尾随逗号，或因长度限制被截断，
+// 严格解析会让整个文本块（fail_fast 时是整批）失败
+// 主要功能：
+// - decodeJSON: 先严格解析，失败时定位第一个 JSON 值并修复常见缺陷后再解析
+// - repairJSON: 单引号转双引号、删除尾随逗号、忽略 JSON 之后的文字、截断的输出回退到最后一个完整元素并补全括号
+// - 兼容多种结构：三元组可以是 [s, p, o] 列表或对象，实体字段可以是 entities 或 named_entities，也可以直接返回三元组列表
+// - completeJSON: 修复后仍无法解析时把解析错误发回 LLM 要求重新输出，最多 maxRepairs 次
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/example/go-scaffold/pkg/llm"
+	"github.com/example/go-scaffold/pkg/prompt"
+)
+
+// SetMaxRepairs 设置输出无法解析时重新请求 LLM 的最大次数（<= 0 时不重新请求）
+func (e *Extractor) SetMaxRepairs(n int) {
+	if n < 0 {
+		n = 0
+	}
+	e.maxRepairs = n
+}
+
+// completeJSON 用提示词 p 调用 LLM 并用 parse 解析输出
+// 解析失败时带着之前的对话和错误信息重新请求，最多 e.maxRepairs 次
+func (e *Extractor) completeJSON(ctx context.Context, p string, parse func(response string) error) error {
+	response, err := e.llmClient.Complete(ctx, p)
+	if err != nil {
+		return fmt.Errorf("llm complete: %w", err)
+	}
+
+	messages := []llm.Message{{Role: llm.RoleUser, Content: p}}
+	for attempt := 0; ; attempt++ {
+		err = parse(response)
+		if err == nil {
+			return nil
+		}
+		if attempt >= e.maxRepairs {
+			return fmt.Errorf("parse response: %w", err)
+		}
+
+		repair, renderErr := e.prompts.Render(prompt.OpenIERepair, map[string]any{"Error": err.Error()})
+		if renderErr != nil {
+			return renderErr
+		}
+		messages = append(messages,
+			llm.Message{Role: llm.RoleAssistant, Content: response},
+			llm.Message{Role: llm.RoleUser, Content: repair},
+		)
+		response, err = e.llmClient.Chat(ctx, messages, llm.ChatOptions{})
+		if err != nil {
+			return fmt.Errorf("llm chat: %w", err)
+		}
+	}
+}
+
+// rawExtraction LLM 输出的宽松结构
+type rawExtraction struct {
+	Entities      []json.RawMessage `json:"entities"`
+	NamedEntities []json.RawMessage `json:"named_entities"`
+	Triples       []json.RawMessage `json:"triples"`
+}
+
+// decodeExtraction 解析 LLM 输出为宽松结构
+func decodeExtraction(response string) (*rawExtraction, error) {
+	var value json.RawMessage
+	if err := decodeJSON(response, &value); err != nil {
+		return nil, err
+	}
+
+	var raw rawExtraction
+	if bytes.HasPrefix(bytes.TrimSpace(value), []byte("[")) {
+		// 直接返回了三元组列表
+		if err := json.Unmarshal(value, &raw.Triples); err != nil {
+			return nil, fmt.Errorf("unmarshal triples: %w", err)
+		}
+		return &raw, nil
+	}
+
+	if err := json.Unmarshal(value, &raw); err != nil {
+		return nil, fmt.Errorf("unmarshal json: %w", err)
+	}
+	return &raw, nil
+}
+
+// entities 返回实体列表（去重，保持顺序）
+// 每个实体可以是字符串或 {"name": ...} 对象，其他形式忽略
+func (r *rawExtraction) entities() []string {
+	entities := make([]string, 0, len(r.Entities)+len(r.NamedEntities))
+	seen := make(map[string]bool)
+	for _, raw := range append(r.Entities, r.NamedEntities...) {
+		var entity string
+		if err := json.Unmarshal(raw, &entity); err != nil {
+			var named struct {
+				Name string `json:"name"`
+			}
+			if err := json.Unmarshal(raw, &named); err != nil {
+				continue
+			}
+			entity = named.Name
+		}
+
+		entity = strings.TrimSpace(entity)
+		if entity != "" && !seen[entity] {
+			seen[entity] = true
+			entities = append(entities, entity)
+		}
+	}
+	return entities
+}
+
+// triples 返回三元组列表，以及因结构不对或字段为空而跳过的数量
+func (r *rawExtraction) triples() ([]Triple, int) {
+	triples := make([]Triple, 0, len(r.Triples))
+	malformed := 0
+	for _, raw := range r.Triples {
+		triple, ok := parseTriple(raw)
+		if !ok {
+			malformed++
+			continue
+		}
+		triples = append(triples, triple)
+	}
+	return triples, malformed
+}
+
+// parseTriple 解析单个三元组：[s, p, o] 列表，或含 subject / predicate（或 relation）/ object 的对象
+// 数字和布尔值按文本处理
+func parseTriple(raw json.RawMessage) (Triple, bool) {
+	var triple Triple
+
+	var list []any
+	if err := json.Unmarshal(raw, &list); err == nil {
+		if len(list) != 3 {
+			return Triple{}, false
+		}
+		triple = Triple{Subject: scalarString(list[0]), Predicate: scalarString(list[1]), Object: scalarString(list[2])}
+	} else {
+		var fields map[string]any
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return Triple{}, false
+		}
+		predicate, exists := fields["predicate"]
+		if !exists {
+			predicate = fields["relation"]
+		}
+		triple = Triple{Subject: scalarString(fields["subject"]), Predicate: scalarString(predicate), Object: scalarString(fields["object"])}
+	}
+
+	triple.Subject = strings.TrimSpace(triple.Subject)
+	triple.Predicate = strings.TrimSpace(triple.Predicate)
+	triple.Object = strings.TrimSpace(triple.Object)
+	if triple.Subject == "" || triple.Predicate == "" || triple.Object == "" {
+		return Triple{}, false
+	}
+	return triple, true
+}
+
+// scalarString 把 JSON 标量转为文本，其他类型返回空串
+func scalarString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return ""
+	}
+}
+
+// decodeJSON 解析 LLM 返回的 JSON 到 v，严格解析失败时修复后再试
+// 修复后仍失败时返回严格解析的错误（更能说明原始输出的问题）
+func decodeJSON(response string, v any) error {
+	err := json.Unmarshal([]byte(stripCodeFence(response)), v)
+	if err == nil {
+		return nil
+	}
+
+	repaired, ok := repairJSON(response)
+	if !ok || json.Unmarshal([]byte(repaired), v) != nil {
+		return fmt.Errorf("unmarshal json: %w", err)
+	}
+	return nil
+}
+
+// repairJSON 从 LLM 输出中取出第一个 JSON 对象或数组并修复常见缺陷：
+// - 前后的说明文字、markdown 代码块标记
+// - 单引号字符串、字符串中的换行和 \' 转义
+// - 尾随逗号
+// - 输出被截断：回退到最后一个完整元素并补全括号
+// 找不到 JSON 或括号不匹配时返回 false
+func repairJSON(s string) (string, bool) {
+	start := strings.IndexAny(s, "{[")
+	if start < 0 {
+		return "", false
+	}
+
+	out := make([]byte, 0, len(s)-start)
+	var stack []byte // 未闭合的括号
+
+	// 最近一个逗号的位置和当时未闭合的括号，截断时回退到这里
+	cut := -1
+	var cutStack []byte
+
+	var quote byte // 当前字符串的引号，0 表示不在字符串中
+	for i := start; i < len(s); i++ {
+		c := s[i]
+
+		if quote != 0 {
+			switch {
+			case c == '\\' && i+1 < len(s):
+				if s[i+1] == '\'' {
+					out = append(out, '\'')
+				} else {
+					out = append(out, c, s[i+1])
+				}
+				i++
+			case c == quote:
+				out = append(out, '"')
+				quote = 0
+			case c == '"':
+				// 单引号字符串中的双引号
+				out = append(out, '\\', '"')
+			case c == '\n':
+				out = append(out, '\\', 'n')
+			default:
+				out = append(out, c)
+			}
+			continue
+		}
+
+		switch c {
+		case '"', '\'':
+			quote = c
+			out = append(out, '"')
+		case '{', '[':
+			stack = append(stack, c)
+			out = append(out, c)
+		case '}', ']':
+			if len(stack) == 0 || stack[len(stack)-1] != openingBracket(c) {
+				return "", false
+			}
+			out = append(trimTrailingComma(out), c)
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				// 忽略 JSON 之后的内容
+				return string(out), true
+			}
+		case ',':
+			cut = len(out)
+			cutStack = append(cutStack[:0], stack...)
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+
+	// 输出被截断
+	if cut < 0 {
+		return "", false
+	}
+	out = trimTrailingComma(out[:cut])
+	for i := len(cutStack) - 1; i >= 0; i-- {
+		out = append(out, closingBracket(cutStack[i]))
+	}
+	return string(out), true
+}
+
+// trimTrailingComma 删除末尾的逗号（及其后的空白）
+func trimTrailingComma(out []byte) []byte {
+	trimmed := bytes.TrimRight(out, " \t\r\n")
+	if len(trimmed) > 0 && trimmed[len(trimmed)-1] == ',' {
+		return trimmed[:len(trimmed)-1]
+	}
+	return out
+}
+
+func openingBracket(c byte) byte {
+	if c == '}' {
+		return '{'
+	}
+	return '['
+}
+
+func closingBracket(c byte) byte {
+	if c == '{' {
+		return '}'
+	}
+	return ']'
+}
+
+// stripCodeFence 清理响应（移除可能的 markdown 代码块标记）
+func stripCodeFence(response string) string {
+	response = strings.TrimSpace(response)
+	response = strings.TrimPrefix(response, "```json")
+	response = strings.TrimPrefix(response, "```")
+	response = strings.TrimSuffix(response, "```")
+	return strings.TrimSpace(response)
+}
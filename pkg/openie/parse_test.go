@@ -0,0 +1,262 @@
+package openie
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/example/go-scaffold/pkg/llm"
+)
+
+// fakeClient 测试用 LLM 客户端：按提示词返回预设回复，并记录调用次数
+type fakeClient struct {
+	complete func(prompt string) (string, error)          // nil 时返回空 JSON 对象
+	chat     func(messages []llm.Message) (string, error) // nil 时返回空 JSON 对象
+
+	mu            sync.Mutex
+	completeCalls int
+	chatCalls     int
+	chatMessages  [][]llm.Message
+}
+
+func (c *fakeClient) Complete(ctx context.Context, prompt string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	c.completeCalls++
+	c.mu.Unlock()
+	if c.complete == nil {
+		return "{}", nil
+	}
+	return c.complete(prompt)
+}
+
+func (c *fakeClient) Chat(ctx context.Context, messages []llm.Message, opts llm.ChatOptions) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	c.chatCalls++
+	c.chatMessages = append(c.chatMessages, append([]llm.Message(nil), messages...))
+	c.mu.Unlock()
+	if c.chat == nil {
+		return "{}", nil
+	}
+	return c.chat(messages)
+}
+
+// TestDecodeExtraction 容错解析：说明文字、单引号、尾随逗号、截断、列表形式的三元组、named_entities 字段
+func TestDecodeExtraction(t *testing.T) {
+	tests := []struct {
+		name          string
+		response      string
+		wantEntities  []string
+		wantTriples   []Triple
+		wantMalformed int
+		wantErr       bool
+	}{
+		{
+			name:         "strict",
+			response:     `{"named_entities": ["爱因斯坦", "相对论"], "triples": [["爱因斯坦", "提出", "相对论"]]}`,
+			wantEntities: []string{"爱因斯坦", "相对论"},
+			wantTriples:  []Triple{{"爱因斯坦", "提出", "相对论"}},
+		},
+		{
+			name:         "code fence",
+			response:     "```json\n{\"entities\": [\"Ulm\"], \"triples\": []}\n```",
+			wantEntities: []string{"Ulm"},
+			wantTriples:  []Triple{},
+		},
+		{
+			name: "prose around json",
+			response: "Sure! Here is the result:\n" +
+				`{"entities": ["Einstein", "Ulm"], "triples": [["Einstein", "born in", "Ulm"]]}` +
+				"\nLet me know if you need anything else {or more}.",
+			wantEntities: []string{"Einstein", "Ulm"},
+			wantTriples:  []Triple{{"Einstein", "born in", "Ulm"}},
+		},
+		{
+			name:         "single quotes",
+			response:     `{'entities': ['Einstein', 'the "Annus" papers'], 'triples': [['Einstein', 'wrote', 'Einstein\'s papers']]}`,
+			wantEntities: []string{"Einstein", `the "Annus" papers`},
+			wantTriples:  []Triple{{"Einstein", "wrote", "Einstein's papers"}},
+		},
+		{
+			name:         "trailing commas",
+			response:     "{\"entities\": [\"A\", \"B\",\n], \"triples\": [[\"A\", \"p\", \"B\"], ],}",
+			wantEntities: []string{"A", "B"},
+			wantTriples:  []Triple{{"A", "p", "B"}},
+		},
+		{
+			name:          "truncated inside triple",
+			response:      `{"entities": ["A", "B", "C"], "triples": [["A", "p", "B"], ["B", "q", "C"], ["C", "r`,
+			wantEntities:  []string{"A", "B", "C"},
+			wantTriples:   []Triple{{"A", "p", "B"}, {"B", "q", "C"}},
+			wantMalformed: 1, // 截断的 ["C"]
+		},
+		{
+			name:         "truncated between triples",
+			response:     `{"named_entities": ["A", "B"], "triples": [["A", "p", "B"], `,
+			wantEntities: []string{"A", "B"},
+			wantTriples:  []Triple{{"A", "p", "B"}},
+		},
+		{
+			name:        "array of triples",
+			response:    `[["A", "p", "B"], {"subject": "B", "relation": "q", "object": "C"}, {"subject": "C", "predicate": "born in", "object": 1879}]`,
+			wantTriples: []Triple{{"A", "p", "B"}, {"B", "q", "C"}, {"C", "born in", "1879"}},
+		},
+		{
+			name:         "entity objects and duplicates",
+			response:     `{"entities": ["A", {"name": " B "}, "", 3], "named_entities": ["A", "C"], "triples": []}`,
+			wantEntities: []string{"A", "B", "C"},
+			wantTriples:  []Triple{},
+		},
+		{
+			name:          "malformed triples skipped",
+			response:      `{"entities": [], "triples": [["A", "p"], ["A", " ", "B"], "A p B", {"subject": "A", "object": "B"}, [" A ", "p", "B "]]}`,
+			wantEntities:  []string{},
+			wantTriples:   []Triple{{"A", "p", "B"}},
+			wantMalformed: 4,
+		},
+		{
+			name:     "no json",
+			response: "I could not find any entities.",
+			wantErr:  true,
+		},
+		{
+			name:     "mismatched brackets",
+			response: `{"entities": ["A"}`,
+			wantErr:  true,
+		},
+		{
+			name:     "truncated before any element",
+			response: `{"entities": ["A`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := decodeExtraction(tt.response)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", raw)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeExtraction: %v", err)
+			}
+
+			if entities := raw.entities(); len(entities) != len(tt.wantEntities) || len(entities) > 0 && !reflect.DeepEqual(entities, tt.wantEntities) {
+				t.Errorf("entities = %q, want %q", entities, tt.wantEntities)
+			}
+			triples, malformed := raw.triples()
+			if len(triples) != len(tt.wantTriples) || len(triples) > 0 && !reflect.DeepEqual(triples, tt.wantTriples) {
+				t.Errorf("triples = %+v, want %+v", triples, tt.wantTriples)
+			}
+			if malformed != tt.wantMalformed {
+				t.Errorf("malformed = %d, want %d", malformed, tt.wantMalformed)
+			}
+		})
+	}
+}
+
+// TestRepairJSON 修复后的文本
+func TestRepairJSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{`text {"a": 1} more {"b": 2}`, `{"a": 1}`, true},
+		{`{'a': 'it\'s "x"'}`, `{"a": "it's \"x\""}`, true},
+		{"{\"a\": \"line1\nline2\"}", `{"a": "line1\nline2"}`, true},
+		{`[1, 2, ]`, `[1, 2]`, true},
+		{`{"a": [1, 2, {"b": 3`, `{"a": [1, 2]}`, true},
+		{`{"a": "}"}`, `{"a": "}"}`, true},
+		{`no json`, ``, false},
+		{`[1}`, ``, false},
+		{`{"a": 1`, ``, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := repairJSON(tt.in)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("repairJSON(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestCompleteJSONMaxRepairs 输出无法解析时带着错误重新请求，最多 maxRepairs 次
+func TestCompleteJSONMaxRepairs(t *testing.T) {
+	for _, maxRepairs := range []int{0, 1, 3} {
+		client := &fakeClient{
+			complete: func(string) (string, error) { return "not json", nil },
+			chat:     func([]llm.Message) (string, error) { return "still not json", nil },
+		}
+		extractor := NewExtractor(client)
+		extractor.SetMaxRepairs(maxRepairs)
+
+		if _, err := extractor.Extract(context.Background(), "爱因斯坦提出了相对论。"); err == nil {
+			t.Fatalf("maxRepairs %d: expected error", maxRepairs)
+		}
+		if client.completeCalls != 1 || client.chatCalls != maxRepairs {
+			t.Errorf("maxRepairs %d: %d complete calls, %d chat calls", maxRepairs, client.completeCalls, client.chatCalls)
+		}
+		// 每次重新请求都带着之前的全部对话
+		for i, messages := range client.chatMessages {
+			if len(messages) != 3+2*i {
+				t.Errorf("maxRepairs %d: chat %d has %d messages, want %d", maxRepairs, i, len(messages), 3+2*i)
+			}
+			if messages[len(messages)-2].Role != llm.RoleAssistant || messages[len(messages)-1].Role != llm.RoleUser {
+				t.Errorf("maxRepairs %d: chat %d does not end with assistant response and repair prompt", maxRepairs, i)
+			}
+		}
+	}
+}
+
+// TestCompleteJSONRepaired 重新请求得到可解析的输出后停止
+func TestCompleteJSONRepaired(t *testing.T) {
+	attempts := 0
+	client := &fakeClient{
+		complete: func(string) (string, error) { return "not json", nil },
+		chat: func(messages []llm.Message) (string, error) {
+			attempts++
+			if !strings.Contains(messages[len(messages)-1].Content, "invalid character") {
+				return "", errors.New("repair prompt does not contain the parse error")
+			}
+			if attempts < 2 {
+				return "still not json", nil
+			}
+			return `{"entities": ["爱因斯坦"], "triples": [["爱因斯坦", "提出", "相对论"]]}`, nil
+		},
+	}
+	extractor := NewExtractor(client)
+	extractor.SetMaxRepairs(5)
+
+	result, err := extractor.Extract(context.Background(), "爱因斯坦提出了相对论。")
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if client.chatCalls != 2 || len(result.Triples) != 1 {
+		t.Errorf("%d chat calls, result %+v", client.chatCalls, result)
+	}
+}
+
+// TestCompleteJSONLLMError LLM 调用失败时不重新请求
+func TestCompleteJSONLLMError(t *testing.T) {
+	client := &fakeClient{complete: func(string) (string, error) { return "", errors.New("boom") }}
+	extractor := NewExtractor(client)
+
+	if _, err := extractor.Extract(context.Background(), "text"); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("err = %v, want llm error", err)
+	}
+	if client.chatCalls != 0 {
+		t.Errorf("%d chat calls, want 0", client.chatCalls)
+	}
+}
@@ -0,0 +1,248 @@
+package openie
+
+// rule.go - 基于词典和规则的抽取器
+// 用途：不调用 LLM，用词典匹配和句式规则抽取实体和三元组
+// 适用场景：低成本基线、LLM 失败时的回退、无 API 费用地索引大规模语料
+// 主要功能：
+// - 词典（gazetteer）：文本中出现的已知实体名都作为实体
+// - 句式规则：如 "X是Y"、"X出生于Y"、"X发现了Y"，每条规则对应一个谓语（"但是"、"就是"、"不是" 等不当作 "是"）
+// - 按句子和分句切分后逐条匹配，代词主语（他/她/它）指向上一个主语
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Rule 句式规则：正则需包含命名分组 subject 和 object
+type Rule struct {
+	Predicate string         // 谓语
+	Pattern   *regexp.Regexp // 匹配单个分句
+}
+
+// RuleExtractor 基于词典和规则的抽取器
+type RuleExtractor struct {
+	gazetteer map[string]bool // 已知实体名
+	rules     []Rule          // 按顺序匹配，每个分句只使用第一条匹配的规则
+	maxLen    int             // 主语 / 宾语的最大字符数，过长的匹配多半不是实体
+}
+
+// 分句切分：句末标点、分号、逗号和换行
+var clauseSeparator = regexp.MustCompile(`[。！？；，,;!?\n]+|\.\s+`)
+
+// 代词主语，指向上一个分句的主语
+var pronouns = map[string]bool{"他": true, "她": true, "它": true, "其": true, "he": true, "she": true, "it": true}
+
+// 主语开头的连词，抽取时去掉（"但是爱因斯坦" -> "爱因斯坦"）
+var subjectConnectives = []string{"但是", "于是", "可是", "然而", "而且", "并且", "所以", "因此", "不过", "此外"}
+
+// copulaAdverbs 与 "是" 组成副词、连词或否定的字（但是、于是、就是、还是、可是、总是、不是、也是……）
+// "是" 规则的主语不能以这些字结尾，否则 "但是他很有名" 会被拆成 (但, 是, 他很有名)
+const copulaAdverbs = "但于就还可总只而不也都正要若凡或倒算真老像"
+
+// 宾语开头常见的量词短语，抽取时去掉（"一位物理学家" -> "物理学家"）
+var objectPrefixes = []string{"一位", "一个", "一名", "一种", "一家", "一座", "一部", "a ", "an ", "the "}
+
+// DefaultRules 默认句式规则（中文为主，附少量英文）
+// 更具体的规则排在前面，"是" 放在最后；"是" 前面是副词或连词（copulaAdverbs）时不匹配
+func DefaultRules() []Rule {
+	patterns := []struct {
+		predicate string
+		pattern   string
+	}{
+		{"出生于", `^(?P<subject>.+?)(?:出生于|生于|出生在)(?P<object>.+)$`},
+		{"毕业于", `^(?P<subject>.+?)毕业于(?P<object>.+)$`},
+		{"发现了", `^(?P<subject>.+?)发现了(?P<object>.+)$`},
+		{"发明了", `^(?P<subject>.+?)发明了(?P<object>.+)$`},
+		{"提出了", `^(?P<subject>.+?)提出了(?P<object>.+)$`},
+		{"创立了", `^(?P<subject>.+?)(?:创立了|创办了|创建了)(?P<object>.+)$`},
+		{"获得了", `^(?P<subject>.+?)获得了(?P<object>.+)$`},
+		{"位于", `^(?P<subject>.+?)位于(?P<object>.+)$`},
+		{"属于", `^(?P<subject>.+?)属于(?P<object>.+)$`},
+		{"担任", `^(?P<subject>.+?)担任(?P<object>.+)$`},
+		{"是", `^(?P<subject>.*?[^` + copulaAdverbs + `])是(?P<object>.+)$`},
+		{"born in", `(?i)^(?P<subject>.+?) was born in (?P<object>.+)$`},
+		{"discovered", `(?i)^(?P<subject>.+?) discovered (?P<object>.+)$`},
+		{"invented", `(?i)^(?P<subject>.+?) invented (?P<object>.+)$`},
+		{"founded", `(?i)^(?P<subject>.+?) founded (?P<object>.+)$`},
+		{"is", `(?i)^(?P<subject>.+?) (?:is|was) (?P<object>.+)$`},
+	}
+
+	rules := make([]Rule, len(patterns))
+	for i, p := range patterns {
+		rules[i] = Rule{Predicate: p.predicate, Pattern: regexp.MustCompile(p.pattern)}
+	}
+	return rules
+}
+
+// NewRuleExtractor 创建规则抽取器（使用 DefaultRules，词典为空）
+func NewRuleExtractor() *RuleExtractor {
+	return &RuleExtractor{
+		gazetteer: make(map[string]bool),
+		rules:     DefaultRules(),
+		maxLen:    30,
+	}
+}
+
+// AddEntities 向词典添加已知实体名
+func (e *RuleExtractor) AddEntities(names ...string) {
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			e.gazetteer[name] = true
+		}
+	}
+}
+
+// AddRule 添加句式规则（排在已有规则之后）
+// pattern 需包含命名分组 subject 和 object
+func (e *RuleExtractor) AddRule(predicate, pattern string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("compile pattern: %w", err)
+	}
+	if re.SubexpIndex("subject") < 0 || re.SubexpIndex("object") < 0 {
+		return fmt.Errorf("pattern must contain named groups subject and object: %s", pattern)
+	}
+
+	e.rules = append(e.rules, Rule{Predicate: predicate, Pattern: re})
+	return nil
+}
+
+// SetRules 替换全部句式规则
+func (e *RuleExtractor) SetRules(rules []Rule) {
+	e.rules = rules
+}
+
+// Extract 从文本中提取实体和关系
+func (e *RuleExtractor) Extract(ctx context.Context, text string) (*ExtractionResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &ExtractionResult{Entities: []string{}, Triples: []Triple{}}
+	seen := make(map[string]bool)
+	addEntity := func(entity string) {
+		if !seen[entity] {
+			seen[entity] = true
+			result.Entities = append(result.Entities, entity)
+		}
+	}
+
+	// 词典匹配
+	for name := range e.gazetteer {
+		if strings.Contains(text, name) {
+			addEntity(name)
+		}
+	}
+
+	// 句式规则
+	lastSubject := ""
+	for _, clause := range clauseSeparator.Split(text, -1) {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+
+		triple, ok := e.matchClause(clause)
+		if !ok {
+			continue
+		}
+		if pronouns[strings.ToLower(triple.Subject)] {
+			if lastSubject == "" {
+				continue
+			}
+			triple.Subject = lastSubject
+		}
+		lastSubject = triple.Subject
+
+		addEntity(triple.Subject)
+		addEntity(triple.Object)
+		result.Triples = append(result.Triples, triple)
+	}
+
+	// 词典匹配的顺序不确定，按在文本中首次出现的位置排序
+	sortByPosition(result.Entities, text)
+
+	return result, nil
+}
+
+// matchClause 用第一条匹配的规则抽取分句中的三元组
+func (e *RuleExtractor) matchClause(clause string) (Triple, bool) {
+	for _, rule := range e.rules {
+		match := rule.Pattern.FindStringSubmatch(clause)
+		if match == nil {
+			continue
+		}
+
+		subject := trimConnective(cleanArgument(match[rule.Pattern.SubexpIndex("subject")]))
+		object := cleanArgument(match[rule.Pattern.SubexpIndex("object")])
+		if !e.validArgument(subject) || !e.validArgument(object) {
+			continue
+		}
+
+		return Triple{Subject: subject, Predicate: rule.Predicate, Object: object}, true
+	}
+	return Triple{}, false
+}
+
+// validArgument 主语 / 宾语非空且不过长
+func (e *RuleExtractor) validArgument(s string) bool {
+	n := utf8.RuneCountInString(s)
+	return n > 0 && n <= e.maxLen
+}
+
+// cleanArgument 去掉首尾空白、引号、句末标点和量词短语
+func cleanArgument(s string) string {
+	s = strings.Trim(strings.TrimSpace(s), "\"'“”‘’「」《》")
+	s = strings.TrimRight(s, ".。")
+	lower := strings.ToLower(s)
+	for _, prefix := range objectPrefixes {
+		if strings.HasPrefix(lower, prefix) && len(s) > len(prefix) {
+			s = s[len(prefix):]
+			break
+		}
+	}
+	return strings.TrimSpace(s)
+}
+
+// trimConnective 去掉主语开头的连词
+func trimConnective(s string) string {
+	for _, connective := range subjectConnectives {
+		if strings.HasPrefix(s, connective) {
+			return strings.TrimSpace(s[len(connective):])
+		}
+	}
+	return s
+}
+
+// sortByPosition 按实体在文本中首次出现的位置排序（未出现的排在最后，保持原顺序）
+func sortByPosition(entities []string, text string) {
+	position := func(entity string) int {
+		if i := strings.Index(text, entity); i >= 0 {
+			return i
+		}
+		return len(text)
+	}
+	// 插入排序：实体数量很少，且需要稳定排序
+	for i := 1; i < len(entities); i++ {
+		for j := i; j > 0 && position(entities[j]) < position(entities[j-1]); j-- {
+			entities[j], entities[j-1] = entities[j-1], entities[j]
+		}
+	}
+}
+
+// ExtractBatch 批量提取（规则匹配很快，逐个处理）
+func (e *RuleExtractor) ExtractBatch(ctx context.Context, texts []string) (*BatchResult, error) {
+	results := make([]*ExtractionResult, len(texts))
+	for i, text := range texts {
+		result, err := e.Extract(ctx, text)
+		if err != nil {
+			return nil, fmt.Errorf("extract text %d: %w", i, err)
+		}
+		results[i] = result
+	}
+	return &BatchResult{Results: results}, nil
+}
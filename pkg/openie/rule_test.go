@@ -0,0 +1,261 @@
+package openie
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestRuleExtractor 句式规则、连词和副词、代词主语
+func TestRuleExtractor(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []Triple
+	}{
+		{
+			name: "specific rules",
+			text: "爱因斯坦出生于乌尔姆。居里夫人发现了镭，爱迪生发明了电灯。",
+			want: []Triple{{"爱因斯坦", "出生于", "乌尔姆"}, {"居里夫人", "发现了", "镭"}, {"爱迪生", "发明了", "电灯"}},
+		},
+		{
+			name: "copula with classifier",
+			text: "居里夫人是一位物理学家。",
+			want: []Triple{{"居里夫人", "是", "物理学家"}},
+		},
+		{
+			name: "adverbs and conjunctions",
+			text: "但是他很有名。于是实验成功了。这就是答案。他总是迟到。还是算了吧。可是没有结果。只是巧合。",
+			want: []Triple{},
+		},
+		{
+			name: "negation",
+			text: "牛顿不是化学家。他也是数学家。",
+			want: []Triple{},
+		},
+		{
+			name: "leading conjunction",
+			text: "但是爱因斯坦是物理学家。",
+			want: []Triple{{"爱因斯坦", "是", "物理学家"}},
+		},
+		{
+			name: "pronoun carry over",
+			text: "居里夫人出生于华沙。她发现了镭，但是她是波兰人。",
+			want: []Triple{{"居里夫人", "出生于", "华沙"}, {"居里夫人", "发现了", "镭"}, {"居里夫人", "是", "波兰人"}},
+		},
+		{
+			name: "pronoun without antecedent",
+			text: "她发现了镭。",
+			want: []Triple{},
+		},
+		{
+			name: "english",
+			text: "Marie Curie discovered radium. She was a physicist.",
+			want: []Triple{{"Marie Curie", "discovered", "radium"}, {"Marie Curie", "is", "physicist"}},
+		},
+		{
+			name: "argument too long",
+			text: "这一位在十九世纪末期于法国巴黎的一间简陋实验室里长期工作的波兰裔女科学家发现了镭。",
+			want: []Triple{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewRuleExtractor().Extract(context.Background(), tt.text)
+			if err != nil {
+				t.Fatalf("Extract: %v", err)
+			}
+			if len(result.Triples) != len(tt.want) || len(tt.want) > 0 && !reflect.DeepEqual(result.Triples, tt.want) {
+				t.Errorf("triples = %+v, want %+v", result.Triples, tt.want)
+			}
+			for _, triple := range result.Triples {
+				if !containsString(result.Entities, triple.Subject) || !containsString(result.Entities, triple.Object) {
+					t.Errorf("entities %q do not include %+v", result.Entities, triple)
+				}
+			}
+		})
+	}
+}
+
+// TestRuleExtractorGazetteer 词典中出现在文本里的实体按出现位置排序
+func TestRuleExtractorGazetteer(t *testing.T) {
+	extractor := NewRuleExtractor()
+	extractor.AddEntities("诺贝尔奖", " 华沙 ", "", "巴黎")
+
+	result, err := extractor.Extract(context.Background(), "居里夫人出生于华沙，两次获得诺贝尔奖。")
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	want := []string{"居里夫人", "华沙", "诺贝尔奖"}
+	if !reflect.DeepEqual(result.Entities, want) {
+		t.Errorf("entities = %q, want %q", result.Entities, want)
+	}
+}
+
+// TestRuleExtractorAddRule 自定义规则排在默认规则之后；缺少命名分组或无法编译的规则返回错误
+func TestRuleExtractorAddRule(t *testing.T) {
+	extractor := NewRuleExtractor()
+	if err := extractor.AddRule("师从", `^(?P<subject>.+?)师从(?P<object>.+)$`); err != nil {
+		t.Fatalf("AddRule: %v", err)
+	}
+	if err := extractor.AddRule("x", `^(?P<subject>.+)x(.+)$`); err == nil {
+		t.Error("expected error for missing object group")
+	}
+	if err := extractor.AddRule("x", `(?P<subject>`); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+
+	result, err := extractor.Extract(context.Background(), "玻尔师从卢瑟福。")
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if want := []Triple{{"玻尔", "师从", "卢瑟福"}}; !reflect.DeepEqual(result.Triples, want) {
+		t.Errorf("triples = %+v, want %+v", result.Triples, want)
+	}
+
+	extractor.SetRules(nil)
+	result, _ = extractor.Extract(context.Background(), "玻尔师从卢瑟福。")
+	if len(result.Triples) != 0 {
+		t.Errorf("triples = %+v after SetRules(nil)", result.Triples)
+	}
+}
+
+// TestRuleExtractorBatch 批量结果与输入对应，ctx 取消时返回错误
+func TestRuleExtractorBatch(t *testing.T) {
+	texts := []string{"爱因斯坦出生于乌尔姆。", "", "居里夫人发现了镭。"}
+	batch, err := NewRuleExtractor().ExtractBatch(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("ExtractBatch: %v", err)
+	}
+	if len(batch.Results) != 3 || len(batch.Results[0].Triples) != 1 || len(batch.Results[1].Triples) != 0 || batch.Results[2].Triples[0].Object != "镭" {
+		t.Errorf("results = %+v", batch.Results)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewRuleExtractor().ExtractBatch(ctx, texts); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+// stubExtractor 测试用抽取器：文本包含 fail 时失败
+type stubExtractor struct {
+	name string
+	fail string
+}
+
+func (e *stubExtractor) Extract(ctx context.Context, text string) (*ExtractionResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if e.fail != "" && strings.Contains(text, e.fail) {
+		return nil, errors.New(e.name + " failed")
+	}
+	return &ExtractionResult{Entities: []string{e.name + ":" + text}, Triples: []Triple{}}, nil
+}
+
+func (e *stubExtractor) ExtractBatch(ctx context.Context, texts []string) (*BatchResult, error) {
+	batch := &BatchResult{Results: make([]*ExtractionResult, len(texts))}
+	for i, text := range texts {
+		result, err := e.Extract(ctx, text)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			batch.Failed = append(batch.Failed, FailedChunk{Index: i, Text: text, Attempts: 1, Err: err})
+			continue
+		}
+		batch.Results[i] = result
+	}
+	return batch, nil
+}
+
+// TestFallbackExtractor 主抽取器失败的文本块改用备用抽取器
+func TestFallbackExtractor(t *testing.T) {
+	ctx := context.Background()
+	extractor := NewFallbackExtractor(&stubExtractor{name: "primary", fail: "x"}, &stubExtractor{name: "fallback", fail: "xx"})
+
+	result, err := extractor.Extract(ctx, "a")
+	if err != nil || result.Entities[0] != "primary:a" {
+		t.Errorf("Extract(a) = %+v, %v", result, err)
+	}
+	result, err = extractor.Extract(ctx, "x")
+	if err != nil || result.Entities[0] != "fallback:x" {
+		t.Errorf("Extract(x) = %+v, %v", result, err)
+	}
+	if _, err := extractor.Extract(ctx, "xx"); err == nil || !strings.Contains(err.Error(), "primary failed") || !strings.Contains(err.Error(), "fallback failed") {
+		t.Errorf("Extract(xx) err = %v, want both errors", err)
+	}
+
+	batch, err := extractor.ExtractBatch(ctx, []string{"a", "x", "xx", "b"})
+	if err != nil {
+		t.Fatalf("ExtractBatch: %v", err)
+	}
+	var got []string
+	for _, result := range batch.Results {
+		if result == nil {
+			got = append(got, "")
+		} else {
+			got = append(got, result.Entities[0])
+		}
+	}
+	if want := []string{"primary:a", "fallback:x", "", "primary:b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("results = %q, want %q", got, want)
+	}
+	if len(batch.Failed) != 1 || batch.Failed[0].Index != 2 || !strings.Contains(batch.Failed[0].Err.Error(), "fallback failed") {
+		t.Errorf("failed = %+v", batch.Failed)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := extractor.Extract(canceled, "a"); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+// TestFallbackExtractorWholeBatch 主抽取器整批失败（fail_fast）时整批回退到规则抽取器
+func TestFallbackExtractorWholeBatch(t *testing.T) {
+	client := &fakeClient{complete: func(prompt string) (string, error) {
+		if strings.Contains(prompt, "居里夫人") {
+			return "", errors.New("llm unavailable")
+		}
+		return `{"entities": ["llm"], "triples": []}`, nil
+	}}
+
+	texts := []string{"爱因斯坦出生于乌尔姆。", "居里夫人发现了镭。"}
+
+	// fail_fast：整批回退
+	extractor := NewFallbackExtractor(NewExtractor(client), NewRuleExtractor())
+	batch, err := extractor.ExtractBatch(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("ExtractBatch: %v", err)
+	}
+	if batch.Results[0].Triples[0].Subject != "爱因斯坦" || batch.Results[1].Triples[0].Subject != "居里夫人" {
+		t.Errorf("results = %+v, want rule extraction for every chunk", batch.Results)
+	}
+
+	// skip：只有失败的文本块回退
+	primary := NewExtractor(client)
+	if err := primary.SetErrorPolicy(ErrorPolicySkip, 0); err != nil {
+		t.Fatal(err)
+	}
+	batch, err = NewFallbackExtractor(primary, NewRuleExtractor()).ExtractBatch(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("ExtractBatch: %v", err)
+	}
+	if batch.Results[0].Entities[0] != "llm" || batch.Results[1].Triples[0].Subject != "居里夫人" || len(batch.Failed) != 0 {
+		t.Errorf("results = %+v, failed = %+v", batch.Results, batch.Failed)
+	}
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
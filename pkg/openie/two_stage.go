@@ -0,0 +1,222 @@
+package openie
+
+// two_stage.go - 两阶段抽取（HippoRAG 论文的做法）
+// 用途：先做命名实体识别（NER），再在给定实体列表的约束下抽取三元组，减少主语/宾语不在实体列表中的情况
+// 主要功能：
+// - SetMode: 切换一次性抽取 / 两阶段抽取
+// - 两个阶段都使用 few-shot 示例提示词（prompt 包的 openie_ner / openie_triples 模板）
+// - 校验三元组：主语和宾语必须是识别出的实体，能修复的修复（大小写/空白/引号差异、以完整词的形式包含唯一实体），否则丢弃
+// - ValidationStats: 累计的校验统计（总数、保留、修复、丢弃）
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/example/go-scaffold/pkg/prompt"
+)
+
+// ExtractionMode 抽取模式
+type ExtractionMode string
+
+const (
+	// ModeSingle 一次性抽取实体和三元组
+	ModeSingle ExtractionMode = "single"
+	// ModeTwoStage 先 NER，再在实体列表约束下抽取三元组
+	ModeTwoStage ExtractionMode = "two_stage"
+)
+
+// ValidationStats 两阶段模式下的三元组校验统计
+type ValidationStats struct {
+	Total    int // LLM 返回的三元组数
+	Kept     int // 主语和宾语都在实体列表中，原样保留
+	Repaired int // 修复后保留
+	Dropped  int // 无法修复而丢弃（含字段缺失的三元组）
+}
+
+// validationStats 并发安全的累计统计
+type validationStats struct {
+	mu    sync.Mutex
+	stats ValidationStats
+}
+
+func (s *validationStats) add(delta ValidationStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stats.Total += delta.Total
+	s.stats.Kept += delta.Kept
+	s.stats.Repaired += delta.Repaired
+	s.stats.Dropped += delta.Dropped
+}
+
+// SetMode 设置抽取模式
+func (e *Extractor) SetMode(mode ExtractionMode) error {
+	switch mode {
+	case ModeSingle, ModeTwoStage:
+	default:
+		return fmt.Errorf("unknown extraction mode: %q", mode)
+	}
+
+	e.mode = mode
+	return nil
+}
+
+// ValidationStats 返回两阶段模式下累计的三元组校验统计（缓存命中的文本块不计入）
+func (e *Extractor) ValidationStats() ValidationStats {
+	e.stats.mu.Lock()
+	defer e.stats.mu.Unlock()
+
+	return e.stats.stats
+}
+
+// extractTwoStage 两阶段抽取：NER -> 三元组 -> 校验
+func (e *Extractor) extractTwoStage(ctx context.Context, text string) (*ExtractionResult, error) {
+	// 阶段 1: 命名实体识别
+	p, err := e.prompts.Render(prompt.OpenIENER, map[string]any{"Text": text})
+	if err != nil {
+		return nil, err
+	}
+	var entities []string
+	err = e.completeJSON(ctx, p, func(response string) error {
+		raw, err := decodeExtraction(response)
+		if err != nil {
+			return err
+		}
+		entities = raw.entities()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ner: %w", err)
+	}
+	if len(entities) == 0 {
+		return &ExtractionResult{Entities: []string{}, Triples: []Triple{}}, nil
+	}
+
+	// 阶段 2: 在实体列表约束下抽取三元组
+	p, err = e.prompts.Render(prompt.OpenIETriples, map[string]any{"Text": text, "Entities": entities})
+	if err != nil {
+		return nil, err
+	}
+	var raw []Triple
+	var malformed int
+	err = e.completeJSON(ctx, p, func(response string) error {
+		extraction, err := decodeExtraction(response)
+		if err != nil {
+			return err
+		}
+		raw, malformed = extraction.triples()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("triples: %w", err)
+	}
+
+	// 阶段 3: 校验
+	triples, stats := validateTriples(entities, raw)
+	stats.Total += malformed
+	stats.Dropped += malformed
+	e.stats.add(stats)
+
+	return &ExtractionResult{Entities: entities, Triples: triples}, nil
+}
+
+// validateTriples 校验三元组的主语和宾语是否在实体列表中
+// 能修复的替换为对应实体，无法修复的丢弃；重复的三元组只保留一个
+func validateTriples(entities []string, triples []Triple) ([]Triple, ValidationStats) {
+	stats := ValidationStats{Total: len(triples)}
+
+	exact := make(map[string]bool, len(entities))
+	normalized := make(map[string]string, len(entities))
+	for _, entity := range entities {
+		exact[entity] = true
+		normalized[normalizeEntity(entity)] = entity
+	}
+
+	// resolve 返回端点对应的实体，以及是否经过修复
+	resolve := func(endpoint string) (string, bool, bool) {
+		if exact[endpoint] {
+			return endpoint, false, true
+		}
+		if entity, exists := normalized[normalizeEntity(endpoint)]; exists {
+			return entity, true, true
+		}
+		// 端点中以完整词的形式恰好包含一个实体（如 "the city of Paris" -> "Paris"）
+		// 词的一部分不算（"Parisian cuisine" 不是 "Paris"），中文没有词边界，只有前后不是文字时才算
+		match := ""
+		for _, entity := range entities {
+			if containsWord(normalizeEntity(endpoint), normalizeEntity(entity)) {
+				if match != "" && match != entity {
+					return "", false, false
+				}
+				match = entity
+			}
+		}
+		return match, match != "", match != ""
+	}
+
+	kept := make([]Triple, 0, len(triples))
+	seen := make(map[Triple]bool)
+	for _, triple := range triples {
+		subject, subjectRepaired, subjectOK := resolve(triple.Subject)
+		object, objectRepaired, objectOK := resolve(triple.Object)
+		if !subjectOK || !objectOK || subject == object {
+			stats.Dropped++
+			continue
+		}
+
+		triple.Subject = subject
+		triple.Object = object
+		if seen[triple] {
+			stats.Dropped++
+			continue
+		}
+		seen[triple] = true
+
+		if subjectRepaired || objectRepaired {
+			stats.Repaired++
+		} else {
+			stats.Kept++
+		}
+		kept = append(kept, triple)
+	}
+
+	return kept, stats
+}
+
+// containsWord s 中是否有前后都不是字母或数字的 word
+func containsWord(s, word string) bool {
+	if word == "" {
+		return false
+	}
+	for offset := 0; ; {
+		i := strings.Index(s[offset:], word)
+		if i < 0 {
+			return false
+		}
+		start := offset + i
+		end := start + len(word)
+
+		before, _ := utf8.DecodeLastRuneInString(s[:start])
+		after, _ := utf8.DecodeRuneInString(s[end:])
+		if !isWordRune(before) && !isWordRune(after) {
+			return true
+		}
+		_, width := utf8.DecodeRuneInString(s[start:])
+		offset = start + width
+	}
+}
+
+// isWordRune 是否为词的组成字符（DecodeRune 在字符串首尾返回 RuneError，不算）
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// normalizeEntity 用于宽松比较的实体形式：小写、去掉首尾引号和标点、合并空白
+func normalizeEntity(s string) string {
+	s = strings.Trim(strings.TrimSpace(s), "\"'“”‘’.,。，")
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
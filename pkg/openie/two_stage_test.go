@@ -0,0 +1,145 @@
+package openie
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// TestValidateTriples 精确匹配、规范化匹配、完整词匹配、歧义和自环
+func TestValidateTriples(t *testing.T) {
+	entities := []string{"Paris", "France", "Lyon", "Marie Curie", "巴黎", "法国"}
+
+	tests := []struct {
+		name      string
+		triples   []Triple
+		want      []Triple
+		wantStats ValidationStats
+	}{
+		{
+			name:      "exact",
+			triples:   []Triple{{"Paris", "capital of", "France"}, {"巴黎", "位于", "法国"}},
+			want:      []Triple{{"Paris", "capital of", "France"}, {"巴黎", "位于", "法国"}},
+			wantStats: ValidationStats{Total: 2, Kept: 2},
+		},
+		{
+			name:      "normalized",
+			triples:   []Triple{{" paris ", "capital of", `"FRANCE".`}, {"marie  curie", "lived in", "“Paris”"}},
+			want:      []Triple{{"Paris", "capital of", "France"}, {"Marie Curie", "lived in", "Paris"}},
+			wantStats: ValidationStats{Total: 2, Repaired: 2},
+		},
+		{
+			name:      "whole word substring",
+			triples:   []Triple{{"the city of Paris", "capital of", "France"}, {"Dr. Marie Curie", "worked in", "paris, the capital"}},
+			want:      []Triple{{"Paris", "capital of", "France"}, {"Marie Curie", "worked in", "Paris"}},
+			wantStats: ValidationStats{Total: 2, Repaired: 2},
+		},
+		{
+			name: "partial word dropped",
+			triples: []Triple{
+				{"Parisian cuisine", "popular in", "France"},
+				{"Paris", "near", "Lyonnais"},
+				{"法国巴黎", "是", "首都"},
+				{"Curie", "born in", "Poland"},
+			},
+			want:      []Triple{},
+			wantStats: ValidationStats{Total: 4, Dropped: 4},
+		},
+		{
+			name:      "chinese with punctuation",
+			triples:   []Triple{{"巴黎（首都）", "位于", "法国"}},
+			want:      []Triple{{"巴黎", "位于", "法国"}},
+			wantStats: ValidationStats{Total: 1, Repaired: 1},
+		},
+		{
+			name:      "ambiguous",
+			triples:   []Triple{{"Paris and Lyon", "cities of", "France"}},
+			want:      []Triple{},
+			wantStats: ValidationStats{Total: 1, Dropped: 1},
+		},
+		{
+			name:      "self loop",
+			triples:   []Triple{{"Paris", "is", "the city of Paris"}, {"France", "is", "France"}},
+			want:      []Triple{},
+			wantStats: ValidationStats{Total: 2, Dropped: 2},
+		},
+		{
+			name:      "duplicate after repair",
+			triples:   []Triple{{"Paris", "capital of", "France"}, {"paris", "capital of", "France"}},
+			want:      []Triple{{"Paris", "capital of", "France"}},
+			wantStats: ValidationStats{Total: 2, Kept: 1, Dropped: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, stats := validateTriples(entities, tt.triples)
+			if len(got) != len(tt.want) || len(got) > 0 && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("triples = %+v, want %+v", got, tt.want)
+			}
+			if stats != tt.wantStats {
+				t.Errorf("stats = %+v, want %+v", stats, tt.wantStats)
+			}
+		})
+	}
+}
+
+// TestContainsWord 只匹配前后不是字母或数字的位置
+func TestContainsWord(t *testing.T) {
+	tests := []struct {
+		s, word string
+		want    bool
+	}{
+		{"the city of paris", "paris", true},
+		{"paris", "paris", true},
+		{"parisian cuisine", "paris", false},
+		{"parisian and paris", "paris", true},
+		{"paris2024", "paris", false},
+		{"(paris)", "paris", true},
+		{"法国巴黎", "巴黎", false},
+		{"巴黎，法国", "巴黎", true},
+		{"anything", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := containsWord(tt.s, tt.word); got != tt.want {
+			t.Errorf("containsWord(%q, %q) = %v, want %v", tt.s, tt.word, got, tt.want)
+		}
+	}
+}
+
+// TestExtractTwoStage 两阶段抽取：NER 结果约束三元组，校验统计包含结构不对的三元组
+func TestExtractTwoStage(t *testing.T) {
+	responses := []string{
+		`{"named_entities": ["Marie Curie", "Paris", "radium"]}`,
+		`{"triples": [["Marie Curie", "discovered", "radium"], ["Curie", "lived in", "the city of Paris"], ` +
+			`["Marie Curie", "born in", "Warsaw"], ["Parisian labs", "studied", "radium"], ["radium"]]}`,
+	}
+	calls := 0
+	client := &fakeClient{complete: func(string) (string, error) {
+		response := responses[calls]
+		calls++
+		return response, nil
+	}}
+
+	extractor := NewExtractor(client)
+	if err := extractor.SetMode(ModeTwoStage); err != nil {
+		t.Fatal(err)
+	}
+	result, err := extractor.Extract(context.Background(), "Marie Curie discovered radium in Paris.")
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+
+	want := []Triple{{"Marie Curie", "discovered", "radium"}}
+	if !reflect.DeepEqual(result.Triples, want) || len(result.Entities) != 3 {
+		t.Errorf("result = %+v, want triples %+v", result, want)
+	}
+	if stats := extractor.ValidationStats(); stats != (ValidationStats{Total: 5, Kept: 1, Dropped: 4}) {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	if err := extractor.SetMode("three_stage"); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
@@ -0,0 +1,195 @@
+package prompt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// 引入模板注册表之前硬编码在各包中的提示词（golden），默认模板集必须逐字渲染出相同的结果
+
+const (
+	goldenParagraph = `Radio City is India's first private FM radio station and was started on 3 July 2001. It plays Hindi, English and regional songs. Radio City recently forayed into New Media in May 2008 with the launch of a music portal - PlanetRadiocity.com that offers music related news, videos, songs, and other music-related features.`
+	goldenEntities  = `{"named_entities": ["Radio City", "India", "3 July 2001", "Hindi", "English", "May 2008", "PlanetRadiocity.com"]}`
+	goldenTriples   = `{"triples": [["Radio City", "located in", "India"], ["Radio City", "started on", "3 July 2001"], ["Radio City", "plays songs in", "Hindi"], ["Radio City", "plays songs in", "English"], ["Radio City", "launched", "PlanetRadiocity.com"], ["PlanetRadiocity.com", "launched in", "May 2008"]]}`
+)
+
+func goldenExtract(text string) string {
+	return fmt.Sprintf(`Extract entities and relationships from the following text.
+Return the result in JSON format with two fields:
+1. "entities": a list of all entities (nouns, proper nouns)
+2. "triples": a list of relationship triples, each with "subject", "predicate", "object"
+
+Text: %s
+
+Return only valid JSON, no additional text.`, text)
+}
+
+func goldenNER(text string) string {
+	return fmt.Sprintf(`Your task is to extract named entities from the given paragraph.
+Respond with a JSON object containing a single field "named_entities": a list of entity strings.
+
+Paragraph:
+%s
+
+%s
+
+Paragraph:
+%s
+
+Return only valid JSON, no additional text.`, goldenParagraph, goldenEntities, text)
+}
+
+func goldenTriplePrompt(text string, entities []string) string {
+	entityJSON, _ := json.Marshal(map[string][]string{"named_entities": entities})
+	return fmt.Sprintf(`Your task is to construct an RDF (Resource Description Framework) graph from the given paragraph and named entity list.
+Respond with a JSON object containing a single field "triples": a list of [subject, predicate, object] triples.
+The subject and the object of every triple must be taken exactly from the named entity list.
+Clearly resolve pronouns to their specific names.
+
+Paragraph:
+%s
+
+%s
+
+%s
+
+Paragraph:
+%s
+
+%s
+
+Return only valid JSON, no additional text.`, goldenParagraph, goldenEntities, goldenTriples, text, entityJSON)
+}
+
+func goldenRepair(err error) string {
+	return fmt.Sprintf(`Your previous response could not be parsed: %v
+Return the complete result again as valid JSON only, no additional text.`, err)
+}
+
+func goldenRerank(query string, facts []string) string {
+	var factsText strings.Builder
+	for j, fact := range facts {
+		factsText.WriteString(fmt.Sprintf("%d. %s\n", j+1, fact))
+	}
+	return fmt.Sprintf(`给定查询："%s"
+
+请对以下事实按相关性排序（最相关的排在前面）：
+%s
+
+只返回排序后的序号，用逗号分隔。例如：3,1,4,2,5
+
+排序结果：`, query, factsText.String())
+}
+
+func goldenQA(context, query string) string {
+	return fmt.Sprintf(`基于以下文档回答问题。请仔细阅读所有文档，找出相关信息并进行推理。
+
+文档:
+%s
+
+问题: %s
+
+请一步步思考，然后给出简洁的答案。
+
+答案:`, context, query)
+}
+
+func goldenRAGQA(context, query string) string {
+	return fmt.Sprintf(`基于以下文档回答问题。如果文档中没有足够信息，请说明。
+
+文档:
+%s
+
+问题: %s
+
+答案:`, context, query)
+}
+
+// TestDefaultMatchesBaseline 默认模板集与原有提示词逐字相同
+func TestDefaultMatchesBaseline(t *testing.T) {
+	text := "Marie Curie discovered radium in Paris."
+	entities := []string{"Marie Curie", "radium", `"Paris"`}
+	facts := []string{"Marie Curie discovered radium", "居里夫人 出生于 华沙"}
+	context := "[1] 居里夫人出生于华沙。\n[2] 她发现了镭。"
+	parseErr := errors.New("invalid character '}' looking for beginning of value")
+
+	tests := []struct {
+		name string
+		data map[string]any
+		want string
+	}{
+		{OpenIEExtract, map[string]any{"Text": text}, goldenExtract(text)},
+		{OpenIENER, map[string]any{"Text": text}, goldenNER(text)},
+		{OpenIETriples, map[string]any{"Text": text, "Entities": entities}, goldenTriplePrompt(text, entities)},
+		{OpenIERepair, map[string]any{"Error": parseErr.Error()}, goldenRepair(parseErr)},
+		{Rerank, map[string]any{"Query": "居里夫人在哪里出生？", "Facts": facts}, goldenRerank("居里夫人在哪里出生？", facts)},
+		{QA, map[string]any{"Context": context, "Question": "居里夫人在哪里出生？"}, goldenQA(context, "居里夫人在哪里出生？")},
+		{RAGQA, map[string]any{"Context": context, "Question": "居里夫人在哪里出生？"}, goldenRAGQA(context, "居里夫人在哪里出生？")},
+	}
+
+	for _, r := range []*Registry{Default(), mustRegistry(t, "")} {
+		if r.Language() != LanguageDefault {
+			t.Errorf("language = %q, want %q", r.Language(), LanguageDefault)
+		}
+		for _, tt := range tests {
+			got, err := r.Render(tt.name, tt.data)
+			if err != nil {
+				t.Fatalf("Render %s: %v", tt.name, err)
+			}
+			if got != tt.want {
+				t.Errorf("%s differs from the baseline prompt:\ngot:\n%s\nwant:\n%s", tt.name, got, tt.want)
+			}
+		}
+	}
+}
+
+// TestDefaultTemplateLanguages 默认模板集的版本 ID 与对应语言的内置模板相同；覆盖目录按各模板的语言加载
+func TestDefaultTemplateLanguages(t *testing.T) {
+	r := Default()
+	en := mustRegistry(t, LanguageEnglish)
+	zh := mustRegistry(t, LanguageChinese)
+	for _, name := range names {
+		source := en
+		if defaultLanguages[name] == LanguageChinese {
+			source = zh
+		}
+		got, _ := r.Get(name)
+		want, _ := source.Get(name)
+		if got.ID() != want.ID() {
+			t.Errorf("%s: ID %s, want %s", name, got.ID(), want.ID())
+		}
+	}
+
+	dir := t.TempDir()
+	writeTemplate(t, dir, LanguageEnglish, OpenIEExtract, "en: {{.Text}}")
+	writeTemplate(t, dir, LanguageChinese, OpenIEExtract, "zh: {{.Text}}")
+	writeTemplate(t, dir, LanguageChinese, QA, "zh: {{.Question}}")
+	writeTemplate(t, dir, LanguageEnglish, QA, "en: {{.Question}}")
+	if err := r.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+	if got, _ := r.Render(OpenIEExtract, map[string]any{"Text": "T"}); got != "en: T" {
+		t.Errorf("openie_extract = %q, want the en override", got)
+	}
+	if got, _ := r.Render(QA, map[string]any{"Question": "Q", "Context": ""}); got != "zh: Q" {
+		t.Errorf("qa = %q, want the zh override", got)
+	}
+
+	// 未知模板名仍然报错
+	writeTemplate(t, dir, LanguageEnglish, "summary", "{{.Text}}")
+	if err := Default().LoadDir(dir); err == nil {
+		t.Error("LoadDir with an unknown template: want error")
+	}
+}
+
+func mustRegistry(t *testing.T, language string) *Registry {
+	t.Helper()
+	r, err := NewRegistry(language)
+	if err != nil {
+		t.Fatalf("NewRegistry(%q): %v", language, err)
+	}
+	return r
+}
@@ -0,0 +1,287 @@
+package prompt
+
+// registry.go - 提示词模板注册表
+// 用途：集中管理 OpenIE、重排序和问答使用的提示词，支持多语言和从文件覆盖
+// 主要功能：
+// - 内置英文（en）和中文（zh）模板（templates/<语言>/<名称>.tmpl），基于 text/template
+// - 默认模板集（default）与引入模板之前硬编码的提示词逐字相同：OpenIE 模板用英文，重排序和问答模板用中文
+// - NewRegistry: 按语言加载内置模板
+// - LoadDir / Set: 用文件或字符串覆盖模板
+// - 每个模板带有由内容哈希生成的版本 ID，缓存和评测结果可以据此区分提示词版本
+//
+// 模板名称及可用字段：
+// - openie_extract: .Text                一次性抽取实体和三元组
+// - openie_ner:     .Text                两阶段抽取的 NER 阶段
+// - openie_triples: .Text, .Entities     两阶段抽取的三元组阶段（.Entities 为 []string）
+// - openie_repair:  .Error               输出无法解析时要求重新输出
+// - rerank:         .Query, .Facts       事实重排序（.Facts 为 []string）
+// - qa:             .Context, .Question  HippoRAG 问答
+// - rag_qa:         .Context, .Question  传统 RAG 问答
+//
+// 模板中可用的函数：inc（整数加 1，用于编号）、json（序列化为 JSON）
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/example/go-scaffold/pkg/utils"
+)
+
+// 模板名称
+const (
+	OpenIEExtract = "openie_extract"
+	OpenIENER     = "openie_ner"
+	OpenIETriples = "openie_triples"
+	OpenIERepair  = "openie_repair"
+	Rerank        = "rerank"
+	QA            = "qa"
+	RAGQA         = "rag_qa"
+)
+
+// 内置语言
+const (
+	LanguageEnglish = "en"
+	LanguageChinese = "zh"
+	// LanguageDefault 默认模板集，各模板取自 defaultLanguages 中对应的语言
+	LanguageDefault = "default"
+
+	// DefaultLanguage 默认语言
+	DefaultLanguage = LanguageDefault
+)
+
+// defaultLanguages 默认模板集中各模板使用的内置语言（保持原有提示词不变）
+var defaultLanguages = map[string]string{
+	OpenIEExtract: LanguageEnglish,
+	OpenIENER:     LanguageEnglish,
+	OpenIETriples: LanguageEnglish,
+	OpenIERepair:  LanguageEnglish,
+	Rerank:        LanguageChinese,
+	QA:            LanguageChinese,
+	RAGQA:         LanguageChinese,
+}
+
+// templateExt 模板文件扩展名
+const templateExt = ".tmpl"
+
+//go:embed templates
+var builtinFS embed.FS
+
+// names 所有模板名称
+var names = []string{OpenIEExtract, OpenIENER, OpenIETriples, OpenIERepair, Rerank, QA, RAGQA}
+
+// funcs 模板中可用的函数
+var funcs = template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// Template 提示词模板
+type Template struct {
+	Name     string
+	Language string // 模板内容的语言（默认模板集中为 en 或 zh）
+	Version  string // 模板内容哈希（前 8 位），内容变化时随之变化
+
+	tmpl *template.Template
+}
+
+// ID 返回模板的版本 ID，如 "openie_extract.zh@1a2b3c4d"
+func (t *Template) ID() string {
+	return t.Name + "." + t.Language + "@" + t.Version
+}
+
+// Render 用 data 渲染模板（模板引用了 data 中不存在的字段时返回错误）
+func (t *Template) Render(data any) (string, error) {
+	var sb strings.Builder
+	if err := t.tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("render prompt %s: %w", t.ID(), err)
+	}
+	return sb.String(), nil
+}
+
+// newTemplate 解析模板（去掉文件末尾的换行）
+func newTemplate(name, language, source string) (*Template, error) {
+	source = strings.TrimRight(source, "\r\n")
+
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("parse prompt %s: %w", name, err)
+	}
+
+	return &Template{
+		Name:     name,
+		Language: language,
+		Version:  utils.Hash(source)[:8],
+		tmpl:     tmpl,
+	}, nil
+}
+
+// Registry 一种语言（或默认模板集）的提示词模板集合
+// 创建后只读（LoadDir / Set 应在使用前调用），可在多个 goroutine 间共享
+type Registry struct {
+	language  string
+	templates map[string]*Template
+}
+
+// NewRegistry 按语言加载内置模板
+// language: en、zh 或 default，为空时使用 DefaultLanguage
+func NewRegistry(language string) (*Registry, error) {
+	if language == "" {
+		language = DefaultLanguage
+	}
+
+	if _, err := fs.Stat(builtinFS, "templates/"+language); err != nil && language != LanguageDefault {
+		return nil, fmt.Errorf("unknown prompt language: %q", language)
+	}
+
+	r := &Registry{
+		language:  language,
+		templates: make(map[string]*Template, len(names)),
+	}
+	for _, name := range names {
+		source, err := builtinFS.ReadFile("templates/" + r.templateLanguage(name) + "/" + name + templateExt)
+		if err != nil {
+			return nil, fmt.Errorf("read built-in prompt %s.%s: %w", name, r.templateLanguage(name), err)
+		}
+		if err := r.Set(name, string(source)); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Default 返回默认语言的内置模板
+func Default() *Registry {
+	r, err := NewRegistry(DefaultLanguage)
+	if err != nil {
+		// 内置模板无法解析属于编程错误
+		panic(err)
+	}
+	return r
+}
+
+// Language 返回模板语言（默认模板集返回 default）
+func (r *Registry) Language() string {
+	return r.language
+}
+
+// templateLanguage 返回名为 name 的模板使用的语言
+func (r *Registry) templateLanguage(name string) string {
+	if r.language == LanguageDefault {
+		return defaultLanguages[name]
+	}
+	return r.language
+}
+
+// languages 返回模板集用到的语言
+func (r *Registry) languages() []string {
+	if r.language == LanguageDefault {
+		return []string{LanguageEnglish, LanguageChinese}
+	}
+	return []string{r.language}
+}
+
+// Set 用 source 覆盖名为 name 的模板
+func (r *Registry) Set(name, source string) error {
+	if !isKnownName(name) {
+		return fmt.Errorf("unknown prompt template: %q", name)
+	}
+
+	t, err := newTemplate(name, r.templateLanguage(name), source)
+	if err != nil {
+		return err
+	}
+	r.templates[name] = t
+	return nil
+}
+
+// LoadDir 从目录加载覆盖模板
+// 目录结构与内置模板相同：<dir>/<语言>/<名称>.tmpl，只加载当前语言的子目录，未提供的模板保持内置版本
+// 默认模板集按各模板的语言加载（如 <dir>/en/openie_extract.tmpl、<dir>/zh/qa.tmpl）
+// dir 不存在时返回错误；dir 中没有当前语言的子目录时视为没有覆盖，保持内置模板
+func (r *Registry) LoadDir(dir string) error {
+	if _, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("read prompt dir: %w", err)
+	}
+
+	for _, language := range r.languages() {
+		langDir := filepath.Join(dir, language)
+		entries, err := os.ReadDir(langDir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("read prompt dir: %w", err)
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != templateExt {
+				continue
+			}
+			name := strings.TrimSuffix(entry.Name(), templateExt)
+			if isKnownName(name) && r.templateLanguage(name) != language {
+				// 默认模板集中该模板使用另一种语言
+				continue
+			}
+
+			source, err := os.ReadFile(filepath.Join(langDir, entry.Name()))
+			if err != nil {
+				return fmt.Errorf("read prompt file: %w", err)
+			}
+			if err := r.Set(name, string(source)); err != nil {
+				return fmt.Errorf("load %s: %w", entry.Name(), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Get 返回名为 name 的模板
+func (r *Registry) Get(name string) (*Template, error) {
+	t, exists := r.templates[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown prompt template: %q", name)
+	}
+	return t, nil
+}
+
+// Render 渲染名为 name 的模板
+func (r *Registry) Render(name string, data any) (string, error) {
+	t, err := r.Get(name)
+	if err != nil {
+		return "", err
+	}
+	return t.Render(data)
+}
+
+// Versions 返回所有模板的版本 ID（按名称排序），可随评测结果一起保存
+func (r *Registry) Versions() []string {
+	ids := make([]string, 0, len(r.templates))
+	for _, t := range r.templates {
+		ids = append(ids, t.ID())
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// isKnownName 判断是否为已知的模板名称
+func isKnownName(name string) bool {
+	for _, known := range names {
+		if known == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTemplate 在 dir/<language>/<name>.tmpl 写入模板
+func writeTemplate(t *testing.T, dir, language, name, source string) {
+	t.Helper()
+	langDir := filepath.Join(dir, language)
+	if err := os.MkdirAll(langDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(langDir, name+templateExt), []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// render 渲染 rag_qa 模板
+func render(t *testing.T, r *Registry) string {
+	t.Helper()
+	out, err := r.Render(RAGQA, map[string]any{"Context": "C", "Question": "Q"})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	return out
+}
+
+// TestOverridePrecedence 覆盖顺序：内置 < LoadDir < Set；只加载当前语言的子目录
+func TestOverridePrecedence(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, LanguageEnglish, RAGQA, "dir: {{.Question}}\n")
+	writeTemplate(t, dir, LanguageChinese, RAGQA, "目录: {{.Question}}\n")
+
+	r, err := NewRegistry(LanguageEnglish)
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	builtin := render(t, r)
+	if !strings.HasPrefix(builtin, "Answer the question") {
+		t.Fatalf("built-in en template: %q", builtin)
+	}
+	qaBefore, _ := r.Get(QA)
+
+	if err := r.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+	if got := render(t, r); got != "dir: Q" {
+		t.Errorf("after LoadDir: got %q, want %q", got, "dir: Q")
+	}
+	// 目录中没有的模板保持内置版本
+	if qaAfter, _ := r.Get(QA); qaAfter.ID() != qaBefore.ID() {
+		t.Errorf("qa changed without an override: %s -> %s", qaBefore.ID(), qaAfter.ID())
+	}
+
+	if err := r.Set(RAGQA, "set: {{.Question}}"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if got := render(t, r); got != "set: Q" {
+		t.Errorf("after Set: got %q, want %q", got, "set: Q")
+	}
+}
+
+// TestLoadDirMissingLanguage 覆盖目录中没有当前语言的子目录时保持该语言的内置模板
+func TestLoadDirMissingLanguage(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, LanguageChinese, RAGQA, "目录: {{.Question}}")
+
+	r, err := NewRegistry(LanguageEnglish)
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	if err := r.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+	if r.Language() != LanguageEnglish || !strings.HasPrefix(render(t, r), "Answer the question") {
+		t.Errorf("want built-in en templates, got %s: %q", r.Language(), render(t, r))
+	}
+
+	// 覆盖目录本身不存在时返回错误
+	if err := r.LoadDir(filepath.Join(dir, "missing")); err == nil {
+		t.Error("LoadDir on a missing dir: want error")
+	}
+}
+
+// TestLoadErrors 未知语言、未知模板名和解析失败都返回错误
+func TestLoadErrors(t *testing.T) {
+	if _, err := NewRegistry("fr"); err == nil {
+		t.Error("NewRegistry(fr): want error")
+	}
+
+	r, err := NewRegistry(LanguageChinese)
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	if err := r.Set("summary", "x"); err == nil {
+		t.Error("Set with unknown name: want error")
+	}
+
+	dir := t.TempDir()
+	writeTemplate(t, dir, LanguageChinese, QA, "{{.Question")
+	if err := r.LoadDir(dir); err == nil || !strings.Contains(err.Error(), "qa.tmpl") {
+		t.Errorf("LoadDir with a parse error: got %v", err)
+	}
+
+	// 模板引用不存在的字段时渲染失败
+	if err := r.Set(QA, "{{.Missing}}"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if _, err := r.Render(QA, map[string]any{"Question": "Q"}); err == nil {
+		t.Error("Render with a missing key: want error")
+	}
+}
+
+// TestVersionStability 版本 ID 只由模板内容决定
+func TestVersionStability(t *testing.T) {
+	a, err := NewRegistry(LanguageChinese)
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	b, err := NewRegistry(LanguageChinese)
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	if strings.Join(a.Versions(), ",") != strings.Join(b.Versions(), ",") {
+		t.Errorf("versions differ between registries:\n%v\n%v", a.Versions(), b.Versions())
+	}
+	if len(a.Versions()) != len(names) {
+		t.Errorf("got %d versions, want %d", len(a.Versions()), len(names))
+	}
+
+	qa, _ := a.Get(QA)
+	if !strings.HasPrefix(qa.ID(), "qa.zh@") || len(qa.Version) != 8 {
+		t.Errorf("unexpected ID %q", qa.ID())
+	}
+
+	// 末尾换行不影响版本；内容相同的覆盖模板与内置模板版本相同
+	source, err := builtinFS.ReadFile("templates/zh/qa.tmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Set(QA, strings.TrimRight(string(source), "\n")+"\n\n"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if same, _ := b.Get(QA); same.ID() != qa.ID() {
+		t.Errorf("same content: %s != %s", same.ID(), qa.ID())
+	}
+
+	// 内容变化时版本变化
+	if err := b.Set(QA, string(source)+"请简洁回答。"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if changed, _ := b.Get(QA); changed.ID() == qa.ID() {
+		t.Errorf("changed content kept version %s", changed.ID())
+	}
+
+	// 不同语言的同名模板版本不同
+	en, _ := NewRegistry(LanguageEnglish)
+	if enQA, _ := en.Get(QA); enQA.ID() == qa.ID() {
+		t.Errorf("en and zh share ID %s", enQA.ID())
+	}
+}
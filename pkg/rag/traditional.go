@@ -3,28 +3,36 @@ package rag
 // traditional.go - 传统 RAG 实现
 // 用途：简单的向量相似度检索 + LLM 生成
 // 特点：只基于向量相似度，不考虑实体关系
+// 索引的文档保留调用方指定的文档 ID 和元数据，检索结果随文本一起返回
 
 import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 
+	"github.com/example/go-scaffold/pkg/document"
 	"github.com/example/go-scaffold/pkg/embedding"
 	"github.com/example/go-scaffold/pkg/llm"
+	"github.com/example/go-scaffold/pkg/prompt"
 )
 
 // TraditionalRAG 传统 RAG 系统
 type TraditionalRAG struct {
 	embeddingClient embedding.Client
-	llmClient       *llm.OpenAIClient
+	llmClient       llm.Client
 	store           embedding.VectorStore
 	topK            int
+	prompts         *prompt.Registry
+
+	mu   sync.RWMutex
+	docs map[string]document.Document // 向量 ID -> 文档
 }
 
 // NewTraditionalRAG 创建传统 RAG 实例
 func NewTraditionalRAG(
 	embeddingClient embedding.Client,
-	llmClient *llm.OpenAIClient,
+	llmClient llm.Client,
 	topK int,
 ) *TraditionalRAG {
 	return &TraditionalRAG{
@@ -32,25 +40,48 @@ func NewTraditionalRAG(
 		llmClient:       llmClient,
 		store:           embedding.NewStore(embeddingClient),
 		topK:            topK,
+		prompts:         prompt.Default(),
+		docs:            make(map[string]document.Document),
 	}
 }
 
-// Index 索引文档
+// SetPrompts 设置提示词模板（默认使用默认语言的内置模板）
+func (r *TraditionalRAG) SetPrompts(prompts *prompt.Registry) {
+	r.prompts = prompts
+}
+
+// Index 索引纯文本文档（文档 ID 按内容生成）
 func (r *TraditionalRAG) Index(ctx context.Context, docs []string) error {
+	return r.Insert(ctx, document.FromTexts(docs))
+}
+
+// Insert 索引文档，保留文档 ID 和元数据
+// 文档 ID 为空时按内容生成；向量存储按文本去重，正文相同的文档以最后一个为准
+func (r *TraditionalRAG) Insert(ctx context.Context, docs []document.Document) error {
 	fmt.Println("\n=== 传统 RAG 索引 ===")
 	fmt.Printf("索引 %d 个文档...\n", len(docs))
 
-	ids, err := r.store.Insert(ctx, docs)
+	ids, err := r.store.Insert(ctx, document.Texts(docs))
 	if err != nil {
 		return fmt.Errorf("insert documents: %w", err)
 	}
 
+	r.mu.Lock()
+	for i, id := range ids {
+		doc := docs[i]
+		if doc.ID == "" {
+			doc.ID = document.ID(doc.Text)
+		}
+		r.docs[id] = doc
+	}
+	r.mu.Unlock()
+
 	fmt.Printf("✓ 成功索引 %d 个文档\n", len(ids))
 	return nil
 }
 
-// Retrieve 检索相关文档（仅检索，不生成答案）
-func (r *TraditionalRAG) Retrieve(ctx context.Context, query string) ([]string, []float64, error) {
+// Retrieve 检索相关文档（仅检索，不生成答案），返回的文档带有索引时的文档 ID 和元数据
+func (r *TraditionalRAG) Retrieve(ctx context.Context, query string) ([]document.Document, []float64, error) {
 	fmt.Println("\n=== 传统 RAG 检索过程 ===")
 	fmt.Printf("问题: %s\n\n", query)
 
@@ -72,12 +103,18 @@ func (r *TraditionalRAG) Retrieve(ctx context.Context, query string) ([]string,
 	// 3. 获取文档内容
 	fmt.Println("\n检索结果:")
 	fmt.Println("---")
-	docs := make([]string, len(ids))
+	docs := make([]document.Document, len(ids))
+	r.mu.RLock()
 	for i, id := range ids {
-		content, _ := r.store.GetContent(ctx, id)
-		docs[i] = content
-		fmt.Printf("%d. [相似度: %.4f] %s\n", i+1, scores[i], content)
+		doc, exists := r.docs[id]
+		if !exists {
+			content, _ := r.store.GetContent(ctx, id)
+			doc = document.Document{ID: document.ID(content), Text: content}
+		}
+		docs[i] = doc
+		fmt.Printf("%d. [相似度: %.4f] [%s] %s\n", i+1, scores[i], doc.ID, doc.Text)
 	}
+	r.mu.RUnlock()
 	fmt.Println("---")
 
 	return docs, scores, nil
@@ -93,18 +130,14 @@ func (r *TraditionalRAG) Query(ctx context.Context, query string) (string, error
 
 	// 构造提示词
 	fmt.Println("\n步骤 3: 使用 LLM 生成答案...")
-	context := strings.Join(docs, "\n")
-	prompt := fmt.Sprintf(`基于以下文档回答问题。如果文档中没有足够信息，请说明。
-
-文档:
-%s
-
-问题: %s
-
-答案:`, context, query)
+	context := strings.Join(document.Texts(docs), "\n")
+	p, err := r.prompts.Render(prompt.RAGQA, map[string]any{"Context": context, "Question": query})
+	if err != nil {
+		return "", err
+	}
 
 	// 生成答案
-	answer, err := r.llmClient.Complete(ctx, prompt)
+	answer, err := r.llmClient.Complete(ctx, p)
 	if err != nil {
 		return "", fmt.Errorf("generate answer: %w", err)
 	}
@@ -0,0 +1,116 @@
+package rag
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/example/go-scaffold/pkg/document"
+	"github.com/example/go-scaffold/pkg/embedding"
+	"github.com/example/go-scaffold/pkg/llm"
+)
+
+// fakeClient 记录最后一次收到的提示词，固定回复
+type fakeClient struct {
+	prompt string
+}
+
+func (c *fakeClient) Complete(ctx context.Context, prompt string) (string, error) {
+	return c.Chat(ctx, []llm.Message{{Role: llm.RoleUser, Content: prompt}}, llm.ChatOptions{})
+}
+
+func (c *fakeClient) Chat(ctx context.Context, messages []llm.Message, opts llm.ChatOptions) (string, error) {
+	c.prompt = messages[len(messages)-1].Content
+	return "答案", nil
+}
+
+var testDocs = []document.Document{
+	{ID: "einstein.md", Text: "爱因斯坦提出了相对论。", Metadata: map[string]string{document.MetaTitle: "爱因斯坦"}},
+	{Text: "居里夫人发现了镭。"},
+	{ID: "newton.md", Text: "牛顿发现了万有引力定律。", Metadata: map[string]string{document.MetaSource: "newton.md"}},
+}
+
+func newTestRAG(llmClient llm.Client) *TraditionalRAG {
+	return NewTraditionalRAG(embedding.NewLocalClient(64), llmClient, len(testDocs))
+}
+
+// TestInsertKeepsDocuments 检索结果带有索引时的文档 ID 和元数据，未指定 ID 的文档按内容生成
+func TestInsertKeepsDocuments(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRAG(&fakeClient{})
+	if err := r.Insert(ctx, testDocs); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	docs, scores, err := r.Retrieve(ctx, "谁提出了相对论？")
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if len(docs) != len(testDocs) || len(scores) != len(docs) {
+		t.Fatalf("Retrieve returned %d documents, %d scores", len(docs), len(scores))
+	}
+
+	byText := make(map[string]document.Document)
+	for _, doc := range docs {
+		byText[doc.Text] = doc
+	}
+	for _, want := range testDocs {
+		got, exists := byText[want.Text]
+		if !exists {
+			t.Errorf("document %q not retrieved", want.Text)
+			continue
+		}
+		wantID := want.ID
+		if wantID == "" {
+			wantID = document.ID(want.Text)
+		}
+		if got.ID != wantID {
+			t.Errorf("ID of %q = %q, want %q", want.Text, got.ID, wantID)
+		}
+		for key, value := range want.Metadata {
+			if got.Metadata[key] != value {
+				t.Errorf("metadata %s of %q = %q, want %q", key, want.Text, got.Metadata[key], value)
+			}
+		}
+	}
+}
+
+// TestIndex 纯文本索引使用按内容生成的文档 ID
+func TestIndex(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRAG(&fakeClient{})
+	if err := r.Index(ctx, []string{"爱因斯坦提出了相对论。"}); err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+
+	docs, _, err := r.Retrieve(ctx, "相对论")
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if len(docs) != 1 || docs[0].ID != document.ID("爱因斯坦提出了相对论。") {
+		t.Errorf("Retrieve = %+v", docs)
+	}
+}
+
+// TestQuery 生成答案的提示词包含检索到的文档正文和问题
+func TestQuery(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeClient{}
+	r := newTestRAG(client)
+	if err := r.Insert(ctx, testDocs); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	answer, err := r.Query(ctx, "谁提出了相对论？")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if answer != "答案" {
+		t.Errorf("answer = %q", answer)
+	}
+	for _, want := range append(document.Texts(testDocs), "谁提出了相对论？") {
+		if !strings.Contains(client.prompt, want) {
+			t.Errorf("prompt does not contain %q:\n%s", want, client.prompt)
+		}
+	}
+}
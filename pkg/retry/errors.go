@@ -0,0 +1,156 @@
+package retry
+
+// errors.go - API 错误类型
+// 用途：把 HTTP 响应转换为带状态码的类型化错误，区分可重试错误和致命错误
+// 主要功能：
+// - APIError: 非 2xx 响应（状态码、错误信息、服务端要求的等待时间）
+// - CheckResponse: 检查 HTTP 响应并解析 OpenAI 风格的错误体和 Retry-After 头
+// - IsRetryable: 判断错误是否值得重试（429、5xx、网络错误等）
+// - ParseRetryAfter: 解析 Retry-After 头（秒数或 HTTP 日期）
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIError 服务端返回的非 2xx 响应
+type APIError struct {
+	StatusCode int           // HTTP 状态码
+	Type       string        // 错误类型（OpenAI 错误体中的 type）
+	Message    string        // 错误信息
+	RetryAfter time.Duration // 服务端要求的等待时间，0 表示未指定
+}
+
+// Error 实现 error 接口
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error (status %d): %s", e.StatusCode, e.Message)
+}
+
+// Retryable 是否可以重试：限流（429）、超时（408）、冲突（409）和服务端错误（5xx）
+// 其余 4xx（如鉴权失败、请求格式错误）重试也不会成功
+func (e *APIError) Retryable() bool {
+	switch {
+	case e.StatusCode == http.StatusTooManyRequests,
+		e.StatusCode == http.StatusRequestTimeout,
+		e.StatusCode == http.StatusConflict:
+		return true
+	case e.StatusCode >= 500:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsRetryable 判断错误是否可以重试
+// - *APIError: 见 APIError.Retryable
+// - context 取消或超时：不重试
+// - 网络错误、连接被意外关闭：重试
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// CheckResponse 检查 HTTP 响应，状态码不是 2xx 时返回 *APIError
+// body: 已读取的响应体（用于解析错误信息）
+func CheckResponse(resp *http.Response, body []byte) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		RetryAfter: retryAfterFromHeader(resp.Header, time.Now()),
+	}
+
+	// OpenAI 错误体：{"error": {"message": "...", "type": "..."}}
+	var errBody struct {
+		Error *struct {
+			Message string `json:"message"`
+			Type    string `json:"type"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errBody); err == nil && errBody.Error != nil {
+		apiErr.Message = errBody.Error.Message
+		apiErr.Type = errBody.Error.Type
+	} else {
+		apiErr.Message = truncate(strings.TrimSpace(string(body)), 200)
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+
+	return apiErr
+}
+
+// retryAfterFromHeader 从响应头读取等待时间
+// 优先使用 OpenAI 的 retry-after-ms（毫秒），其次是标准的 Retry-After
+func retryAfterFromHeader(header http.Header, now time.Time) time.Duration {
+	if ms := header.Get("Retry-After-Ms"); ms != "" {
+		if value, err := strconv.ParseFloat(ms, 64); err == nil && value > 0 {
+			return time.Duration(value * float64(time.Millisecond))
+		}
+	}
+	return ParseRetryAfter(header.Get("Retry-After"), now)
+}
+
+// ParseRetryAfter 解析 Retry-After 头
+// 支持秒数（如 "30"）和 HTTP 日期（如 "Wed, 21 Oct 2015 07:28:00 GMT"）两种格式，无法解析或已过期时返回 0
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds * float64(time.Second))
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// truncate 截断过长的字符串（按字节，保证不截断 UTF-8 字符）
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !isRuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "..."
+}
+
+// isRuneStart 判断字节是否为 UTF-8 字符的起始字节
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,107 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseRetryAfter 秒数和 HTTP 日期两种格式
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"30", 30 * time.Second},
+		{" 1.5 ", 1500 * time.Millisecond},
+		{"0", 0},
+		{"-5", 0},
+		{"Wed, 21 Oct 2015 07:28:30 GMT", 30 * time.Second},
+		{"Wednesday, 21-Oct-15 07:29:00 GMT", time.Minute},
+		{"Wed, 21 Oct 2015 07:27:00 GMT", 0}, // 已过期
+		{"soon", 0},
+	}
+
+	for _, tt := range tests {
+		if got := ParseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("ParseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+// TestCheckResponse 2xx 返回 nil，其余状态码解析错误体
+func TestCheckResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantMessage string
+		wantType    string
+	}{
+		{name: "ok", status: 200},
+		{name: "created", status: 201},
+		{name: "openai error", status: 429, body: `{"error": {"message": "Rate limit reached", "type": "requests"}}`, wantMessage: "Rate limit reached", wantType: "requests"},
+		{name: "plain text", status: 502, body: "  Bad Gateway from proxy \n", wantMessage: "Bad Gateway from proxy"},
+		{name: "empty body", status: 503, wantMessage: "Service Unavailable"},
+		{name: "long body", status: 500, body: strings.Repeat("错", 100), wantMessage: strings.Repeat("错", 66) + "..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			err := CheckResponse(resp, []byte(tt.body))
+			if tt.status < 300 {
+				if err != nil {
+					t.Fatalf("CheckResponse: %v", err)
+				}
+				return
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("err = %v, want APIError", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Message != tt.wantMessage || apiErr.Type != tt.wantType {
+				t.Errorf("got %+v, want message %q type %q", apiErr, tt.wantMessage, tt.wantType)
+			}
+		})
+	}
+}
+
+// TestIsRetryable 限流、服务端错误和网络错误可重试；其余 4xx 和 ctx 取消不重试
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"429", &APIError{StatusCode: 429}, true},
+		{"408", &APIError{StatusCode: 408}, true},
+		{"409", &APIError{StatusCode: 409}, true},
+		{"500", &APIError{StatusCode: 500}, true},
+		{"503 wrapped", fmt.Errorf("embed: %w", &APIError{StatusCode: 503}), true},
+		{"400", &APIError{StatusCode: 400}, false},
+		{"401", &APIError{StatusCode: 401}, false},
+		{"404", &APIError{StatusCode: 404}, false},
+		{"canceled", fmt.Errorf("send request: %w", context.Canceled), false},
+		{"deadline", context.DeadlineExceeded, false},
+		{"network", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"unexpected eof", fmt.Errorf("read response: %w", io.ErrUnexpectedEOF), true},
+		{"other", errors.New("unmarshal response: invalid character"), false},
+	}
+
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("IsRetryable(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package retry
+
+// limiter.go - 客户端限流
+// 用途：在客户端按每分钟请求数（RPM）和每分钟 token 数（TPM）限流，避免触发服务端 429
+// 主要功能：
+// - Limiter: 两个令牌桶（请求数、token 数），按时间连续补充
+// - Wait: 阻塞直到两个桶都有足够额度，多个 goroutine（以及多个客户端）可共享同一个 Limiter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter 请求数 / token 数限流器（并发安全）
+// nil Limiter 不做任何限制
+type Limiter struct {
+	mu       sync.Mutex
+	requests *bucket // 每分钟请求数，nil 表示不限制
+	tokens   *bucket // 每分钟 token 数，nil 表示不限制
+}
+
+// bucket 令牌桶
+type bucket struct {
+	capacity float64   // 容量（每分钟额度）
+	rate     float64   // 每秒补充量
+	level    float64   // 当前额度
+	last     time.Time // 上次补充时间
+}
+
+// NewLimiter 创建限流器
+// rpm: 每分钟最大请求数，tpm: 每分钟最大 token 数；<= 0 表示不限制该项
+func NewLimiter(rpm, tpm int) *Limiter {
+	now := time.Now()
+	return &Limiter{
+		requests: newBucket(rpm, now),
+		tokens:   newBucket(tpm, now),
+	}
+}
+
+func newBucket(perMinute int, now time.Time) *bucket {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &bucket{
+		capacity: float64(perMinute),
+		rate:     float64(perMinute) / 60,
+		level:    float64(perMinute),
+		last:     now,
+	}
+}
+
+// Wait 阻塞直到可以发送一个预计消耗 tokens 个 token 的请求，并扣除额度
+// 超过每分钟上限的单个请求按上限计算，避免永远等待
+func (l *Limiter) Wait(ctx context.Context, tokens int) error {
+	if l == nil {
+		return nil
+	}
+
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		requestWait := l.requests.reserve(1, now)
+		tokenWait := l.tokens.reserve(float64(tokens), now)
+		if requestWait == 0 && tokenWait == 0 {
+			l.requests.take(1)
+			l.tokens.take(float64(tokens))
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+
+		if err := sleep(ctx, max(requestWait, tokenWait)); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve 补充额度，返回凑够 n 还需等待的时间（0 表示额度充足）
+func (b *bucket) reserve(n float64, now time.Time) time.Duration {
+	if b == nil {
+		return 0
+	}
+
+	b.level = min(b.capacity, b.level+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	n = min(n, b.capacity)
+	if b.level >= n {
+		return 0
+	}
+	wait := time.Duration((n - b.level) / b.rate * float64(time.Second))
+	if wait <= 0 {
+		// 浮点误差导致的极小等待
+		wait = time.Millisecond
+	}
+	return wait
+}
+
+// take 扣除额度（调用前需确认 reserve 返回 0）
+func (b *bucket) take(n float64) {
+	if b == nil {
+		return
+	}
+	b.level -= min(n, b.capacity)
+}
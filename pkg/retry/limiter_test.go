@@ -0,0 +1,94 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestLimiterWait 额度用完后等待补充
+func TestLimiterWait(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewLimiter(0, 600) // 每秒补充 10 个 token
+
+	start := time.Now()
+	if err := limiter.Wait(ctx, 600); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("first Wait took %v, want no wait", elapsed)
+	}
+
+	// 再要 2 个 token 需要等约 200ms
+	if err := limiter.Wait(ctx, 2); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("second Wait returned after %v, want about 200ms", elapsed)
+	}
+}
+
+// TestLimiterWaitConcurrent 多个 goroutine 共享请求数额度
+func TestLimiterWaitConcurrent(t *testing.T) {
+	limiter := NewLimiter(6000, 0) // 每秒补充 100 个请求
+	if err := limiter.Wait(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+	limiter.requests.level = 0
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := limiter.Wait(context.Background(), 0); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 10 个请求至少需要 100ms 的额度
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("10 requests finished after %v, want about 100ms", elapsed)
+	}
+}
+
+// TestLimiterWaitCancel 等待期间取消 ctx 时返回 ctx 的错误
+func TestLimiterWaitCancel(t *testing.T) {
+	limiter := NewLimiter(1, 0)
+	if err := limiter.Wait(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := limiter.Wait(ctx, 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("returned after %v", elapsed)
+	}
+}
+
+// TestLimiterNil nil 限流器和不限制的限流器不等待；超过上限的单个请求按上限计算
+func TestLimiterNil(t *testing.T) {
+	var nilLimiter *Limiter
+	if err := nilLimiter.Wait(context.Background(), 1<<30); err != nil {
+		t.Fatalf("nil limiter: %v", err)
+	}
+
+	start := time.Now()
+	for _, limiter := range []*Limiter{NewLimiter(0, 0), NewLimiter(0, 100)} {
+		if err := limiter.Wait(context.Background(), 1000); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Wait took %v, want no wait", elapsed)
+	}
+}
@@ -0,0 +1,119 @@
+package retry
+
+// retry.go - 重试与指数退避
+// 用途：对可重试的失败（限流、服务端错误、网络错误）自动重试
+// 主要功能：
+// - Policy: 最大尝试次数、初始/最大退避时间、退避倍数、随机抖动
+// - Do: 按策略执行函数，优先遵守服务端返回的 Retry-After，否则指数退避 + 抖动
+// - Wait: 自行实现重试循环时（如对所有错误都重试），在两次尝试之间按同样的规则等待
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Policy 重试策略
+type Policy struct {
+	MaxAttempts int           // 最大尝试次数（含第一次），<= 1 时不重试
+	BaseDelay   time.Duration // 第一次重试前的等待时间
+	MaxDelay    time.Duration // 退避等待时间上限（不限制服务端要求的 Retry-After）
+	Multiplier  float64       // 每次重试等待时间的倍数
+	Jitter      float64       // 随机抖动比例（0.2 表示 ±20%），避免多个客户端同时重试
+}
+
+// DefaultPolicy 默认重试策略：最多 5 次尝试，1s 起步指数退避，上限 60s，±20% 抖动
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts: 5,
+		BaseDelay:   time.Second,
+		MaxDelay:    60 * time.Second,
+		Multiplier:  2,
+		Jitter:      0.2,
+	}
+}
+
+// NoRetry 不重试的策略
+func NoRetry() Policy {
+	return Policy{MaxAttempts: 1}
+}
+
+// Backoff 返回第 attempt 次失败（从 1 开始）后的退避等待时间（含抖动）
+func (p Policy) Backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.BaseDelay) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		delay *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	if delay < 0 {
+		return 0
+	}
+	return time.Duration(delay)
+}
+
+// Do 按策略执行 fn，fn 返回可重试错误（见 IsRetryable）时等待后重试
+// 返回 fn 最后一次的错误；不可重试的错误立即返回；ctx 取消时返回 ctx 的错误
+func Do(ctx context.Context, policy Policy, fn func(ctx context.Context) error) error {
+	attempts := policy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		if !IsRetryable(err) {
+			return err
+		}
+		if attempt >= attempts {
+			if attempts == 1 {
+				return err
+			}
+			return fmt.Errorf("giving up after %d attempts: %w", attempts, err)
+		}
+
+		if err := policy.Wait(ctx, attempt, err); err != nil {
+			return err
+		}
+	}
+}
+
+// Wait 等待第 attempt 次失败（从 1 开始）后的退避时间，ctx 取消时提前返回 ctx 的错误
+// err 为 *APIError 且服务端指定了 Retry-After 时以服务端为准
+func (p Policy) Wait(ctx context.Context, attempt int, err error) error {
+	delay := p.Backoff(attempt)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		delay = apiErr.RetryAfter
+	}
+	return sleep(ctx, delay)
+}
+
+// sleep 等待 d，ctx 取消时提前返回
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
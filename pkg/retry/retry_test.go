@@ -0,0 +1,225 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastPolicy 测试用的短退避策略
+func fastPolicy(attempts int) Policy {
+	return Policy{MaxAttempts: attempts, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond, Multiplier: 2}
+}
+
+// statusServer 依次返回 statuses 中的状态码（用完后返回 200），headers 为每次响应附加的头
+func statusServer(t *testing.T, statuses []int, headers map[string]string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		if n > len(statuses) {
+			fmt.Fprint(w, `{"ok": true}`)
+			return
+		}
+		for key, value := range headers {
+			w.Header().Set(key, value)
+		}
+		w.WriteHeader(statuses[n-1])
+		fmt.Fprintf(w, `{"error": {"message": "status %d", "type": "test_error"}}`, statuses[n-1])
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+// get 与 OpenAI 客户端相同的方式发送一次请求并检查响应
+func get(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return CheckResponse(resp, body)
+}
+
+// TestDoRetries 429 和 5xx 重试后成功，4xx 不重试，尝试次数用完后返回最后的错误
+func TestDoRetries(t *testing.T) {
+	tests := []struct {
+		name        string
+		statuses    []int
+		attempts    int
+		wantCalls   int32
+		wantStatus  int // 期望的 APIError 状态码，0 表示成功
+		wantGivenUp bool
+	}{
+		{name: "rate limited", statuses: []int{429, 429}, attempts: 5, wantCalls: 3},
+		{name: "server errors", statuses: []int{500, 502, 503}, attempts: 5, wantCalls: 4},
+		{name: "timeout and conflict", statuses: []int{408, 409}, attempts: 3, wantCalls: 3},
+		{name: "unauthorized", statuses: []int{401}, attempts: 5, wantCalls: 1, wantStatus: 401},
+		{name: "bad request", statuses: []int{400, 400}, attempts: 5, wantCalls: 1, wantStatus: 400},
+		{name: "gives up", statuses: []int{503, 503, 503, 503}, attempts: 3, wantCalls: 3, wantStatus: 503, wantGivenUp: true},
+		{name: "no retry", statuses: []int{503}, attempts: 1, wantCalls: 1, wantStatus: 503},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := statusServer(t, tt.statuses, nil)
+
+			err := Do(context.Background(), fastPolicy(tt.attempts), func(ctx context.Context) error {
+				return get(ctx, server.URL)
+			})
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("%d calls, want %d", got, tt.wantCalls)
+			}
+
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("Do: %v", err)
+				}
+				return
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.wantStatus {
+				t.Fatalf("err = %v, want APIError with status %d", err, tt.wantStatus)
+			}
+			if apiErr.Message != fmt.Sprintf("status %d", tt.wantStatus) || apiErr.Type != "test_error" {
+				t.Errorf("error body not parsed: %+v", apiErr)
+			}
+			if gaveUp := err != apiErr; gaveUp != tt.wantGivenUp {
+				t.Errorf("err = %v, want giving up %v", err, tt.wantGivenUp)
+			}
+		})
+	}
+}
+
+// TestDoRetryAfter 服务端返回的 Retry-After 优先于退避策略
+func TestDoRetryAfter(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    time.Duration
+	}{
+		{name: "seconds", headers: map[string]string{"Retry-After": "0.2"}, want: 200 * time.Millisecond},
+		{name: "milliseconds", headers: map[string]string{"Retry-After-Ms": "150", "Retry-After": "100"}, want: 150 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := statusServer(t, []int{429}, tt.headers)
+
+			start := time.Now()
+			err := Do(context.Background(), fastPolicy(3), func(ctx context.Context) error {
+				return get(ctx, server.URL)
+			})
+			if err != nil {
+				t.Fatalf("Do: %v", err)
+			}
+			if elapsed := time.Since(start); elapsed < tt.want {
+				t.Errorf("retried after %v, want at least %v", elapsed, tt.want)
+			}
+			if calls.Load() != 2 {
+				t.Errorf("%d calls, want 2", calls.Load())
+			}
+		})
+	}
+}
+
+// TestCheckResponseRetryAfterDate HTTP 日期形式的 Retry-After
+func TestCheckResponseRetryAfterDate(t *testing.T) {
+	server, _ := statusServer(t, []int{503}, map[string]string{
+		"Retry-After": time.Now().Add(3 * time.Second).UTC().Format(http.TimeFormat),
+	})
+
+	var apiErr *APIError
+	if err := get(context.Background(), server.URL); !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want APIError", err)
+	}
+	// HTTP 日期精确到秒
+	if apiErr.RetryAfter <= time.Second || apiErr.RetryAfter > 3*time.Second {
+		t.Errorf("RetryAfter = %v, want about 3s", apiErr.RetryAfter)
+	}
+}
+
+// TestDoCancelDuringBackoff 退避等待期间取消 ctx 时立即返回 ctx 的错误
+func TestDoCancelDuringBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+	}{
+		{name: "backoff"},
+		{name: "retry after", headers: map[string]string{"Retry-After": "3600"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := statusServer(t, []int{503, 503}, tt.headers)
+			policy := Policy{MaxAttempts: 3, BaseDelay: time.Hour}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(20*time.Millisecond, cancel)
+
+			start := time.Now()
+			err := Do(ctx, policy, func(ctx context.Context) error {
+				return get(ctx, server.URL)
+			})
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("err = %v, want context.Canceled", err)
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("returned after %v", elapsed)
+			}
+			if calls.Load() != 1 {
+				t.Errorf("%d calls, want 1", calls.Load())
+			}
+		})
+	}
+}
+
+// TestDoCanceledError fn 返回 ctx 的错误时不重试
+func TestDoCanceledError(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), fastPolicy(5), func(ctx context.Context) error {
+		calls++
+		return fmt.Errorf("send request: %w", context.DeadlineExceeded)
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || calls != 1 {
+		t.Errorf("err = %v after %d calls", err, calls)
+	}
+}
+
+// TestBackoff 指数增长、上限和抖动范围
+func TestBackoff(t *testing.T) {
+	policy := Policy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Multiplier: 2}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, w := range want {
+		if got := policy.Backoff(i + 1); got != w {
+			t.Errorf("Backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+
+	// 倍数小于 1 时按 1 处理
+	if got := (Policy{BaseDelay: time.Second, Multiplier: 0.5}).Backoff(3); got != time.Second {
+		t.Errorf("Backoff with multiplier 0.5 = %v, want 1s", got)
+	}
+
+	policy.Jitter = 0.2
+	for i := 0; i < 100; i++ {
+		if got := policy.Backoff(1); got < 80*time.Millisecond || got > 120*time.Millisecond {
+			t.Fatalf("Backoff with jitter = %v, want 80ms..120ms", got)
+		}
+	}
+}
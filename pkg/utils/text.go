@@ -4,45 +4,92 @@ package utils
 // 用途：文档分块、文本清洗等预处理功能
 // 主要功能：
 // - ChunkText: 将长文档切分成固定大小的块（带重叠）
+// - ChunkTextSpans: 同 ChunkText，并返回每块在原始文本中的位置
 // - CleanText: 清理文本中的多余空白字符
+// - EstimateTokens: 粗略估算 token 数（用于限流）
 
 import (
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 // ChunkText 将文本分割成固定大小的块，支持重叠
 // text: 输入文本
-// chunkSize: 每块的字符数
+// chunkSize: 每块的字符数（按 rune 计数）
 // overlap: 块之间的重叠字符数
 // 返回：文本块数组
 func ChunkText(text string, chunkSize, overlap int) []string {
+	spans := ChunkTextSpans(text, chunkSize, overlap)
+	chunks := make([]string, len(spans))
+	for i, span := range spans {
+		chunks[i] = span.Text
+	}
+	return chunks
+}
+
+// TextChunk 文本块及其在原始文本中的位置
+type TextChunk struct {
+	Text  string // 块内容（已清理空白）
+	Start int    // 在原始文本中的起始字节偏移
+	End   int    // 在原始文本中的结束字节偏移（不含）
+}
+
+// ChunkTextSpans 与 ChunkText 切分方式相同，同时返回每块在原始（未清理）文本中的字节区间
+func ChunkTextSpans(text string, chunkSize, overlap int) []TextChunk {
+	cleaned, offsets := CleanTextWithOffsets(text)
+
+	// span 将清理后文本的 [start, end) 映射回原始文本
+	span := func(start, end int) TextChunk {
+		if start >= end {
+			return TextChunk{Text: cleaned[start:end]}
+		}
+		return TextChunk{
+			Text:  cleaned[start:end],
+			Start: offsets[start],
+			End:   offsets[end-1] + 1,
+		}
+	}
+
 	if chunkSize <= 0 {
-		return []string{text}
+		return []TextChunk{{Text: text, Start: 0, End: len(text)}}
+	}
+
+	// 按字符（rune）计数，块边界总是落在字符边界上，不会切断多字节字符
+	bounds := make([]int, 0, len(cleaned)+1) // 每个字符的起始字节偏移，最后一个元素为 len(cleaned)
+	for i := range cleaned {
+		bounds = append(bounds, i)
+	}
+	bounds = append(bounds, len(cleaned))
+	runeCount := len(bounds) - 1
+
+	if runeCount <= chunkSize {
+		return []TextChunk{span(0, len(cleaned))}
 	}
 
-	text = CleanText(text)
-	if len(text) <= chunkSize {
-		return []string{text}
+	// 重叠不小于块大小时不重叠，避免死循环
+	step := chunkSize - overlap
+	if overlap < 0 || step <= 0 {
+		step = chunkSize
 	}
 
-	var chunks []string
+	var chunks []TextChunk
 	start := 0
 
-	for start < len(text) {
+	for start < runeCount {
 		end := start + chunkSize
-		if end > len(text) {
-			end = len(text)
+		if end > runeCount {
+			end = runeCount
 		}
 
-		chunks = append(chunks, text[start:end])
+		chunks = append(chunks, span(bounds[start], bounds[end]))
 
-		if end == len(text) {
+		if end == runeCount {
 			break
 		}
 
 		// 下一块从 (当前位置 + chunkSize - overlap) 开始
-		start += chunkSize - overlap
+		start += step
 	}
 
 	return chunks
@@ -66,3 +113,51 @@ func CleanText(text string) string {
 
 	return strings.TrimSpace(text)
 }
+
+// CleanTextWithOffsets 与 CleanText 结果相同，同时返回清理后每个字节在原始文本中的字节偏移
+// 合并后的空格对应原始空白序列的第一个字节
+func CleanTextWithOffsets(text string) (string, []int) {
+	var b strings.Builder
+	offsets := make([]int, 0, len(text))
+
+	pendingSpace := -1 // 待输出空格对应的原始偏移，-1 表示没有
+	for i, r := range text {
+		if unicode.IsSpace(r) {
+			if pendingSpace < 0 {
+				pendingSpace = i
+			}
+			continue
+		}
+
+		// 首部空白被丢弃，中间的空白序列合并为一个空格
+		if pendingSpace >= 0 && b.Len() > 0 {
+			b.WriteByte(' ')
+			offsets = append(offsets, pendingSpace)
+		}
+		pendingSpace = -1
+
+		// 非法 UTF-8 字节会被写成 3 字节的 U+FFFD，偏移不能超出原始字节
+		_, width := utf8.DecodeRuneInString(text[i:])
+		size := b.Len()
+		b.WriteRune(r)
+		for k := 0; k < b.Len()-size; k++ {
+			offsets = append(offsets, i+min(k, width-1))
+		}
+	}
+
+	return b.String(), offsets
+}
+
+// EstimateTokens 粗略估算文本的 token 数（用于限流，不需要精确）
+// ASCII 字符约 4 个一个 token，其他字符（如中文）按每个字符一个 token 计算
+func EstimateTokens(text string) int {
+	ascii, other := 0, 0
+	for _, r := range text {
+		if r < 0x80 {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return (ascii+3)/4 + other
+}
@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// TestChunkTextSpansRunes 块大小按字符计数，中文和混合文本的块边界不会切断多字节字符
+func TestChunkTextSpansRunes(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		size    int
+		overlap int
+		want    []string
+	}{
+		{
+			name:    "chinese",
+			text:    "爱因斯坦提出了相对论",
+			size:    4,
+			overlap: 1,
+			want:    []string{"爱因斯坦", "坦提出了", "了相对论"},
+		},
+		{
+			name:    "mixed",
+			text:    "居里 Curie 发现了镭",
+			size:    5,
+			overlap: 0,
+			want:    []string{"居里 Cu", "rie 发", "现了镭"},
+		},
+		{
+			name:    "overlap not smaller than size",
+			text:    "牛顿万有引力",
+			size:    2,
+			overlap: 2,
+			want:    []string{"牛顿", "万有", "引力"},
+		},
+		{
+			name:    "short text",
+			text:    "  镭  ",
+			size:    4,
+			overlap: 1,
+			want:    []string{"镭"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := ChunkTextSpans(tt.text, tt.size, tt.overlap)
+			if len(chunks) != len(tt.want) {
+				t.Fatalf("got %d chunks %q, want %q", len(chunks), ChunkText(tt.text, tt.size, tt.overlap), tt.want)
+			}
+			for i, chunk := range chunks {
+				if chunk.Text != tt.want[i] {
+					t.Errorf("chunk %d = %q, want %q", i, chunk.Text, tt.want[i])
+				}
+				if !utf8.ValidString(chunk.Text) {
+					t.Errorf("chunk %d is not valid UTF-8: %q", i, chunk.Text)
+				}
+			}
+		})
+	}
+}
+
+// TestChunkTextSpansOffsets 每块的字节区间指回原始文本中对应的内容
+func TestChunkTextSpansOffsets(t *testing.T) {
+	text := "\n  爱因斯坦\t提出了\n\n相对论。 Albert  Einstein was born in Ulm,  1879 年。\r\n"
+
+	for _, size := range []int{1, 3, 7, 20} {
+		for _, overlap := range []int{0, 1, 2} {
+			for i, chunk := range ChunkTextSpans(text, size, overlap) {
+				if chunk.Start < 0 || chunk.End > len(text) || chunk.Start >= chunk.End {
+					t.Fatalf("size %d overlap %d chunk %d: invalid span [%d, %d)", size, overlap, i, chunk.Start, chunk.End)
+				}
+				raw := text[chunk.Start:chunk.End]
+				if !utf8.ValidString(raw) {
+					t.Errorf("size %d overlap %d chunk %d: span cuts a character: %q", size, overlap, i, raw)
+				}
+				if got, want := CleanText(raw), strings.TrimSpace(chunk.Text); got != want {
+					t.Errorf("size %d overlap %d chunk %d: span text %q, want %q", size, overlap, i, got, want)
+				}
+			}
+		}
+	}
+}
+
+// TestCleanTextWithOffsets 清理结果与 CleanText 一致，偏移指向原始文本中的同一字符
+func TestCleanTextWithOffsets(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"   ",
+		"爱因斯坦  提出了\n相对论",
+		" \tMarie Curie　发现了镭 ",
+		"invalid \xff byte",
+	} {
+		cleaned, offsets := CleanTextWithOffsets(text)
+		if want := CleanText(text); cleaned != want {
+			t.Errorf("CleanTextWithOffsets(%q) = %q, want %q", text, cleaned, want)
+		}
+		if len(offsets) != len(cleaned) {
+			t.Fatalf("CleanTextWithOffsets(%q): %d offsets for %d bytes", text, len(offsets), len(cleaned))
+		}
+		for i := 1; i < len(offsets); i++ {
+			if offsets[i] < offsets[i-1] || offsets[i] >= len(text) {
+				t.Fatalf("CleanTextWithOffsets(%q): invalid offset %d at %d", text, offsets[i], i)
+			}
+		}
+	}
+}